@@ -0,0 +1,24 @@
+// doctor.go // This file implements the "doctor" command //>
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+// doctor prints the problems found in the setup of bigdl, along with their fixes. It exits with a non-zero code if there are any.
+func doctor(ctx context.Context) {
+	problems := newClient().Diagnose(ctx)
+	if len(problems) == 0 {
+		fmt.Println("No problems found.")
+		return
+	}
+
+	for _, problem := range problems {
+		fmt.Printf("[%s] %s\n", problem.Check, problem.Description)
+		if problem.Fix != "" {
+			fmt.Printf("    Fix: %s\n", problem.Fix)
+		}
+	}
+	errorOut("%d problem(s) found\n", len(problems))
+}
@@ -1,39 +1,18 @@
-// findURL.go // This file implements the findURL function //>
+// findURL.go // This file implements the find_url command //>
 package main
 
 import (
+	"context"
 	"fmt"
-	"net/http"
 )
 
-// findURLCommand returns the URL for the specified binary. We do not use info.go for this because unmarshalling such big files is slower than pinging to see which exists
-func findURLCommand(binaryName string) {
-	url, err := findURL(binaryName)
+// findURLCommand prints the URL for the specified binary.
+func findURLCommand(ctx context.Context, binaryName string) {
+	url, err := newClient().FindURL(ctx, binaryName)
+	fmt.Print("\033[2K\r") // Clean the line
 	if err != nil {
 		errorOut("error: %v\n", err)
 	}
 
 	fmt.Println(url)
 }
-
-// findURL fetches the URL for the specified binary.
-func findURL(binaryName string) (string, error) {
-	iterations := 0
-	for _, Repository := range Repositories {
-		iterations++
-		url := fmt.Sprintf("%s%s", Repository, binaryName)
-		fmt.Printf("\033[2K\r<%d/%d> | Working: Checking if \"%s\" is in the repos.", iterations, len(Repositories), binaryName)
-		resp, err := http.Head(url)
-		if err != nil {
-			return "", err
-		}
-
-		if resp.StatusCode == http.StatusOK {
-			fmt.Printf("\033[2K\r<%d/%d> | Found \"%s\" at %s", iterations, len(Repositories), binaryName, Repository)
-			return url, nil
-		}
-	}
-
-	fmt.Printf("\033[2K\r")
-	return "", fmt.Errorf("Didn't find the SOURCE_URL for [%s]", binaryName)
-}
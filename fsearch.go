@@ -2,84 +2,49 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os/exec"
 	"path/filepath"
-	"strings"
+
+	"github.com/xplshn/bigdl/pkg/bigdl"
 )
 
 // fSearch searches for binaries based on the given search term.
-func fSearch(searchTerm string, limit int) {
-	type tBinary struct {
-		Architecture string `json:"architecture"`
-		Name         string `json:"name"`
-		Description  string `json:"description"`
-	}
-
-	// Fetch metadata
-	var binaries []tBinary
-	err := fetchJSON(RNMetadataURL, &binaries)
+func fSearch(ctx context.Context, searchTerm string, limit int) {
+	client := newClient()
+	searchResults, err := client.Search(ctx, searchTerm)
 	if err != nil {
 		fmt.Println("Failed to fetch and decode binary information:", err)
 		return
 	}
 
-	// Filter binaries based on the search term and architecture
-	searchResultsSet := make(map[string]struct{})
-	for _, binary := range binaries {
-		if strings.Contains(strings.ToLower(binary.Name), strings.ToLower(searchTerm)) || strings.Contains(strings.ToLower(binary.Description), strings.ToLower(searchTerm)) {
-			ext := strings.ToLower(filepath.Ext(binary.Name))
-			base := filepath.Base(binary.Name)
-			if _, excluded := excludedFileTypes[ext]; excluded {
-				continue // Skip this binary if its extension is excluded
-			}
-			if _, excludedName := excludedFileNames[base]; excludedName {
-				continue // Skip this binary if its name is excluded
-			}
-			if binary.Description != "" {
-				entry := fmt.Sprintf("%s - %s", binary.Name, binary.Description)
-				searchResultsSet[entry] = struct{}{}
-			}
-		}
-	}
-
 	// Check if no matching binaries found
-	if len(searchResultsSet) == 0 {
+	if len(searchResults) == 0 {
 		fmt.Printf("No matching binaries found for '%s'.\n", searchTerm)
 		return
-	} else if len(searchResultsSet) > limit {
+	} else if len(searchResults) > limit {
 		fmt.Printf("Too many matching binaries (+%d. [Use --limit before your query]) found for '%s'.\n", limit, searchTerm)
 		return
 	}
 
-	// Convert set to slice for sorting
-	var searchResults []string
-	for entry := range searchResultsSet {
-		searchResults = append(searchResults, entry)
-	}
-
-	// Sort the search results
-	searchResults = sortBinaries(searchResults)
-
 	// Check if the binary exists in the INSTALL_DIR and print results with installation state indicators
-	for _, line := range searchResults {
-		parts := strings.SplitN(line, " - ", 2)
-		name := parts[0]
-		description := parts[1]
-
-		installPath := filepath.Join(InstallDir, name)
-		cachedLocation, _ := ReturnCachedFile(name)
+	for _, result := range searchResults {
+		baseName := filepath.Base(result.InstallPath)
+		cachedLocation := filepath.Join(client.CacheDir(), baseName)
 
 		prefix := "[-]"
-		if fileExists(installPath) {
+		if bigdl.FileExists(result.InstallPath) {
 			prefix = "[i]"
-		} else if path, err := exec.LookPath(name); err == nil && path != "" {
+		} else if client.IsForeignArch() {
+			// $PATH and the cache hold binaries for this machine, they say nothing about the requested architecture
+		} else if path, err := exec.LookPath(baseName); err == nil && path != "" {
 			prefix = "[\033[4mi\033[0m]" // Print [i],'i' is underlined
-		} else if cachedLocation != "" && isExecutable(cachedLocation) {
+		} else if bigdl.IsExecutable(cachedLocation) {
 			prefix = "[c]"
 		}
 
-		truncatePrintf("%s %s - %s ", prefix, name, description)
+		truncatePrintf("%s %s - %s ", prefix, result.Name, result.Description)
 		fmt.Printf("\n") // Escape sequences are truncated too...
 	}
 }
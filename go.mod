@@ -5,11 +5,13 @@ go 1.22.0
 require (
 	github.com/goccy/go-json v0.10.3
 	github.com/schollz/progressbar/v3 v3.14.4
+	golang.org/x/sys v0.21.0
+	lukechampine.com/blake3 v1.4.1
 )
 
 require (
+	github.com/klauspost/cpuid/v2 v2.0.12 // indirect
 	github.com/mitchellh/colorstring v0.0.0-20190213212951-d06e56a500db // indirect
 	github.com/rivo/uniseg v0.4.7 // indirect
-	golang.org/x/sys v0.21.0 // indirect
 	golang.org/x/term v0.21.0 // indirect
 )
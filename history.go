@@ -0,0 +1,42 @@
+// history.go // This file implements the "history" command //>
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// showHistory prints the changes recorded in the history, oldest first. If binaryName is not empty, only its changes are shown.
+func showHistory(binaryName string) {
+	entries, err := newClient().History(binaryName)
+	if err != nil {
+		errorOut("%v\n", err)
+	}
+	if len(entries) == 0 {
+		fmt.Println("No changes were recorded yet.")
+		return
+	}
+
+	// shortSHA256 keeps the checksums readable, 12 characters are plenty to tell them apart
+	shortSHA256 := func(sha256 string) string {
+		if sha256 == "" {
+			return "(none)"
+		}
+		if len(sha256) > 12 {
+			return sha256[:12]
+		}
+		return sha256
+	}
+
+	for _, entry := range entries {
+		name := entry.Binary
+		if filepath.Base(entry.Path) != filepath.Base(entry.Binary) {
+			name = fmt.Sprintf("%s (as %s)", entry.Binary, filepath.Base(entry.Path))
+		}
+		version := entry.Version
+		if version == "" {
+			version = "-"
+		}
+		fmt.Printf("%s  %-7s  %s  %s -> %s  %s  %s\n", entry.Time.Local().Format("2006-01-02 15:04:05"), entry.Command, name, shortSHA256(entry.OldSHA256), shortSHA256(entry.NewSHA256), version, entry.Result)
+	}
+}
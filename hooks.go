@@ -0,0 +1,43 @@
+// hooks.go // This file loads the hooks configured by the user and reports their failures //>
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/xplshn/bigdl/pkg/bigdl"
+)
+
+// hooksFile returns the file the hooks are declared in: $BIGDL_HOOKS, or $XDG_CONFIG_HOME/bigdl/hooks.json
+func hooksFile() string {
+	if hooksFile := os.Getenv("BIGDL_HOOKS"); hooksFile != "" {
+		return hooksFile
+	}
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(configDir, "bigdl", "hooks.json")
+}
+
+// loadHooks adds the hooks of hooksFile() to the options. A malformed file is fatal, so that binaries never get installed without the hooks the user expects to run
+func loadHooks() {
+	file := hooksFile()
+	if file == "" {
+		return
+	}
+	hooks, err := bigdl.LoadHooks(file)
+	if err != nil {
+		errorOut("%v\n", err)
+	}
+	options.Hooks = hooks
+	options.HookOutput = os.Stderr
+}
+
+// printHookErrors reports the hooks that failed, even in silent mode
+func printHookErrors(hookErrors []error) {
+	for _, err := range hookErrors {
+		fmt.Fprintf(os.Stderr, "Hook failed: %v\n", err)
+	}
+}
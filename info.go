@@ -1,64 +1,115 @@
-// info.go // This file implements binInfo, which `info` and `update` use //>
+// info.go // This file implements the "info" command //>
 package main
 
 import (
+	"context"
 	"fmt"
-)
-
-// BinaryInfo struct holds binary metadata used in main.go for the `info`, `update`, `list` functionality
-type BinaryInfo struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Repo        string `json:"repo_url"`
-	ModTime     string `json:"build_date"`
-	Version     string `json:"repo_version"`
-	Updated     string `json:"repo_updated"`
-	Size        string `json:"size"`
-	Extras      string `json:"extra_bins"`
-	SHA256      string `json:"sha256"`
-	Source      string `json:"download_url"`
-}
+	"path/filepath"
+	"strings"
 
-func findBinaryInfo(metadata []map[string]interface{}, binaryName string) (BinaryInfo, bool) {
-	for _, binMap := range metadata {
-		if name, ok := binMap["name"].(string); ok && name == binaryName {
-			description, _ := binMap["description"].(string)
-			repo, _ := binMap["repo_url"].(string)
-			buildDate, _ := binMap["build_date"].(string)
-			version, _ := binMap["repo_version"].(string)
-			updated, _ := binMap["repo_updated"].(string)
-			size, _ := binMap["size"].(string)
-			extras, _ := binMap["extra_bins"].(string)
-			sha256, _ := binMap["sha256"].(string)
-			source, _ := binMap["download_url"].(string)
+	"github.com/xplshn/bigdl/pkg/bigdl"
+)
 
-			return BinaryInfo{
-				Name:        name,
-				Description: description,
-				Repo:        repo,
-				ModTime:     buildDate,
-				Version:     version,
-				Updated:     updated,
-				Size:        size,
-				Extras:      extras,
-				SHA256:      sha256,
-				Source:      source,
-			}, true
+// showInstalled prints the binaries of InstallDir that are in the repos, along with their name in the repos if they were installed under an alias.
+func showInstalled(ctx context.Context) {
+	client := newClient()
+	installedPrograms, err := client.ValidatePrograms(ctx, nil)
+	if err != nil {
+		fmt.Println("Error validating programs:", err)
+		return
+	}
+	for _, program := range installedPrograms {
+		if name := client.CatalogueName(filepath.Join(client.InstallDir(), program)); name != program {
+			fmt.Printf("%s (%s)\n", program, name)
+			continue
 		}
+		fmt.Println(program)
 	}
-	return BinaryInfo{}, false
 }
 
-func getBinaryInfo(binaryName string) (*BinaryInfo, error) {
-	var metadata []map[string]interface{}
-	if err := fetchJSON(RNMetadataURL, &metadata); err != nil {
-		return nil, err
+// showInfo prints the metadata of the binary, and describes the local copy if there's one.
+func showInfo(ctx context.Context, binaryName string) {
+	client := newClient()
+
+	// The binary may have been installed under an alias, which only its install record knows. Repository-qualified names are never aliases
+	installPath := filepath.Join(client.InstallDir(), filepath.Base(binaryName))
+	record, recorded := client.Installed(installPath)
+	recorded = recorded && record.Name != ""
+	if recorded && !strings.Contains(binaryName, "/") {
+		binaryName = record.Name
+	}
+	binaryInfo, err := client.Info(ctx, binaryName)
+	if err != nil {
+		errorOut("%v\n", err)
 	}
 
-	binInfo, found := findBinaryInfo(metadata, binaryName)
-	if !found {
-		return nil, fmt.Errorf("error: info for the requested binary ('%s') not found in the metadata.json file", binaryName)
+	// The file at installPath is only this binary if it was installed as such. Files without a record are assumed to be, unless a specific repo was requested
+	local := !recorded && !strings.Contains(binaryName, "/") && bigdl.FileExists(installPath)
+	if recorded {
+		if installed, err := client.Info(ctx, record.Name); err == nil && installed.Source == binaryInfo.Source {
+			local = true
+		}
 	}
 
-	return &binInfo, nil
+	fmt.Printf("Name: %s\n", binaryInfo.Name)
+	if local && filepath.Base(installPath) != filepath.Base(binaryInfo.Name) {
+		fmt.Printf("Installed as: %s\n", filepath.Base(installPath))
+	}
+	if binaryInfo.Description != "" {
+		fmt.Printf("Description: %s\n", binaryInfo.Description)
+	}
+	if binaryInfo.Repo != "" {
+		fmt.Printf("Repo: %s\n", binaryInfo.Repo)
+	}
+	if binaryInfo.Author != "" {
+		fmt.Printf("Author: %s\n", binaryInfo.Author)
+	}
+	if binaryInfo.Language != "" {
+		fmt.Printf("Language: %s\n", binaryInfo.Language)
+	}
+	if binaryInfo.License != "" {
+		fmt.Printf("License: %s\n", binaryInfo.License)
+	}
+	if binaryInfo.Stars != "" {
+		fmt.Printf("Stars: %s\n", binaryInfo.Stars)
+	}
+	if binaryInfo.Topics != "" {
+		fmt.Printf("Topics: %s\n", binaryInfo.Topics)
+	}
+	if binaryInfo.WebURL != "" {
+		fmt.Printf("Website: %s\n", binaryInfo.WebURL)
+	}
+	if binaryInfo.Released != "" {
+		fmt.Printf("Released: %s\n", binaryInfo.Released)
+	}
+	if binaryInfo.Updated != "" {
+		fmt.Printf("Updated: %s\n", binaryInfo.Updated)
+	}
+	if binaryInfo.Version != "" {
+		fmt.Printf("Version: %s\n", binaryInfo.Version)
+	}
+	if binaryInfo.ModTime != "" {
+		fmt.Printf("Build date: %s\n", binaryInfo.ModTime)
+	}
+	if binaryInfo.Size != "" {
+		fmt.Printf("Size: %s\n", binaryInfo.Size)
+	}
+	if binaryInfo.Source != "" {
+		fmt.Printf("Source: %s\n", binaryInfo.Source)
+	}
+	if binaryInfo.Extras != "" {
+		fmt.Printf("Extras: %s\n", strings.ReplaceAll(binaryInfo.Extras, ",", ", "))
+	}
+	if binaryInfo.SHA256 != "" {
+		fmt.Printf("SHA256: %s\n", binaryInfo.SHA256)
+	}
+	if binaryInfo.B3SUM != "" {
+		fmt.Printf("B3SUM: %s\n", binaryInfo.B3SUM)
+	}
+	// Describe the local copy, if there's one
+	if local && bigdl.FileExists(installPath) {
+		if report, err := client.Inspect(installPath); err == nil {
+			fmt.Printf("Installed: %s\n", report)
+		}
+	}
 }
@@ -2,64 +2,47 @@
 package main
 
 import (
+	"context"
 	"fmt"
-	"os"
-	"path/filepath"
 	"strings"
 )
 
-func installCommand(silent bool, binaryNames string) error {
-	// Disable the progressbar if the installation is to be performed silently
+// installCommand installs the binaries through the client, reporting what was installed unless silent is set.
+func installCommand(ctx context.Context, silent bool, binaryNames []string) error {
+	// Disable the progressbar and the status messages if the installation is to be performed silently
 	if silent {
-		UseProgressBar = false
+		options.ProgressBar = false
+		options.Logf = nil
 	}
-	binaries := strings.Fields(binaryNames)
-	for _, binaryName := range binaries {
-		// Extract the last part of the binaryName to use as the filename
-		fileName := filepath.Base(binaryName)
-
-		// Construct the installPath using the extracted filename
-		installPath := filepath.Join(InstallDir, fileName)
-
-		// Use ReturnCachedFile to check for a cached file
-		if InstallUseCache {
-			cachedFile, err := ReturnCachedFile(binaryName)
-			if err == 0 {
-				// If the cached file exists, use it
-				if !silent {
-					fmt.Printf("Using cached file: %s\n", cachedFile)
-				}
-				// Copy the cached file to the install path
-				if err := copyFile(cachedFile, installPath); err != nil {
-					return fmt.Errorf("error: Could not copy cached file: %v", err)
-				}
-
-				// Set executable bit immediately after copying
-				if err := os.Chmod(installPath, 0o755); err != nil {
-					return fmt.Errorf("failed to set executable bit: %v", err)
-				}
-
-				continue
-			}
-		}
-
-		// If the cached file does not exist, download the binary
-		url, err := findURL(binaryName)
-		if err != nil {
-			errorOut("%v\n", err)
-		}
-
-		if err := fetchBinaryFromURL(url, installPath); err != nil {
-			return fmt.Errorf("%v", err)
-		}
 
+	results, err := newClient().Install(ctx, binaryNames)
+	if !silent {
+		fmt.Print("\033[2K\r") // Clean the line
+	}
+	var hookErrors []error
+	for _, result := range results {
+		hookErrors = append(hookErrors, result.HookErrors...)
 		if !silent {
-			if InstallMessage != "disabled" {
+			switch {
+			case result.FromCache:
+				fmt.Printf("Using cached file: %s\n", result.Path)
+			case result.Archive != "":
+				fmt.Printf("Successfully created %s (from %s)\n", result.Path, result.Archive)
+			case InstallMessage != "disabled":
 				fmt.Print(InstallMessage)
-			} else {
-				fmt.Printf("Successfully created %s\n", installPath)
+			default:
+				fmt.Printf("Successfully created %s\n", result.Path)
+			}
+			if len(result.Extras) > 0 {
+				fmt.Printf("'%s' comes with: %s. They were installed too (use --no-extras to skip them)\n", result.Name, strings.Join(result.Extras, ", "))
 			}
 		}
+		// Dynamically linked binaries and the like are worth a warning, but not in silent installs
+		printWarnings(result.Warnings)
+	}
+	printHookErrors(hookErrors)
+	if ctx.Err() != nil {
+		return fmt.Errorf("interrupted, nothing else was installed")
 	}
-	return nil
+	return err
 }
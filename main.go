@@ -1,34 +1,24 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
-	"path/filepath"
+	"os/signal"
 	"runtime"
 	"strconv"
 	"strings"
+	"syscall"
+
+	"github.com/xplshn/bigdl/pkg/bigdl"
 )
 
 var (
-	// Repositories contains all available repos - This variable is used by findURL.go
-	Repositories []string
-	// MetadataURLs are used for listing the binaries themselves. Not to be confused with R*MetadataURLs.
-	MetadataURLs []string
-	// RNMetadataURL should contain the JSON that describes available binaries for your architecture
-	RNMetadataURL string
-	// ValidatedArch is used in fsearch.go, info.go and main.go to determine which repos to use.
-	ValidatedArch = [3]string{}
-	// InstallDir holds the directory that shall be used for installing, removing, updating, listing with `info`. It takes the value of $INSTALL_DIR if it is set in the user's env, otherwise it is set to have a default value
-	InstallDir = os.Getenv("INSTALL_DIR")
-	// TEMPDIR will be used as the dir to download files to before moving them to a final destination AND as the place that will hold cached binaries downloaded by `run`
-	TEMPDIR = os.Getenv("BIGDL_CACHEDIR")
+	// options configures the bigdl.Client every command works through. init() fills it from the user's env, the flags of main() and of each command adjust it
+	options bigdl.Options
 	// InstallMessage will be printed when installCommand() succeeds
 	InstallMessage = "disabled"
-	// InstallUseCache determines if cached files should be used when requesting an install
-	InstallUseCache = true
-	// UseProgressBar determines if the progressbar is shown or not
-	UseProgressBar = true
 	// DisableTruncation determines if update.go, fsearch.go, etc, truncate their messages or not
 	DisableTruncation = false
 	// Always adds a NEWLINE to text truncated by the truncateSprintf/truncatePrintf function
@@ -36,8 +26,8 @@ var (
 )
 
 const (
-	VERSION   = "1.6.9"                                                               // VERSION to be displayed
-	usagePage = " [-v|-h] [list|install|remove|update|run|info|search|tldr] <-args->" // usagePage to be shown
+	VERSION   = "1.6.9"                                                                                                                   // VERSION to be displayed
+	usagePage = " [-v|-h] [list|install|remove|update|run|info|search|history|undo|doctor|verify|mirror|serve|tldr|self-update] <-args->" // usagePage to be shown
 	// Truncation indicator
 	indicator = "...>"
 	// MaxCacheSize is the limit of binaries which can be stored at TEMP_DIR
@@ -46,54 +36,25 @@ const (
 	BinariesToDelete = 5
 )
 
-// Exclude specified file types and file names, these shall not appear in Lists nor in the Search Results
-var excludedFileTypes = map[string]struct{}{
-	".7z":   {},
-	".bz2":  {},
-	".json": {},
-	".gz":   {},
-	".md":   {},
-	".txt":  {},
-	".tar":  {},
-	".zip":  {},
-	".cfg":  {},
-	".dir":  {},
-	".test": {},
-}
-
-var excludedFileNames = map[string]struct{}{
-	"TEST":                     {},
-	"LICENSE":                  {},
-	"experimentalBinaries_dir": {},
-	"bundles_dir":              {},
-	"blobs_dir":                {},
-	"robotstxt":                {},
-	"bdl.sh":                   {},
-	// Because the repo contains duplicated files. And I do not manage the repo nor plan to implement sha256 filtering :
-	"uroot":             {},
-	"uroot-busybox":     {},
-	"gobusybox":         {},
-	"sysinfo-collector": {},
-	"neofetch":          {},
-	"sh":                {}, // Because in the repo, it is a duplicate of bash and not a POSIX implementation nor the original Thompshon Shell
-}
-
 func init() {
-	if InstallDir == "" {
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			errorOut("error: Failed to get user's Home directory. Maybe set $BIGDL_CACHEDIR? %v\n", err)
-			os.Exit(1)
-		}
-		InstallDir = filepath.Join(homeDir, ".local", "bin")
+	var err error
+	if options, err = bigdl.DefaultOptions(); err != nil {
+		errorOut("error: %v. Maybe set $INSTALL_DIR, $BIGDL_CACHEDIR and $BIGDL_STATEDIR?\n", err)
 	}
-	if TEMPDIR == "" {
-		cacheDir, err := os.UserCacheDir()
-		if err != nil {
-			errorOut("error: Failed to get user's Cache directory. Maybe set $BIGDL_CACHEDIR? %v\n", err)
-			os.Exit(1)
-		}
-		TEMPDIR = filepath.Join(cacheDir, "bigdl_cache")
+	if installDir := os.Getenv("INSTALL_DIR"); installDir != "" {
+		options.InstallDir = installDir
+	}
+	if cacheDir := os.Getenv("BIGDL_CACHEDIR"); cacheDir != "" {
+		options.CacheDir = cacheDir
+	}
+	if stateDir := os.Getenv("BIGDL_STATEDIR"); stateDir != "" {
+		options.StateDir = stateDir
+	}
+	if priority := os.Getenv("BIGDL_REPO_PRIORITY"); priority != "" {
+		options.RepoPriority = strings.Split(priority, ",")
+	}
+	if mirror := os.Getenv("BIGDL_MIRROR"); mirror != "" {
+		options.Repositories = bigdl.MirrorRepositories(mirror)
 	}
 	if os.Getenv("BIGDL_TRUNCATION") == "0" {
 		DisableTruncation = true
@@ -102,33 +63,21 @@ func init() {
 		AddNewLineToTruncateFn = true
 	}
 	if os.Getenv("BIGDL_PRBAR") == "0" {
-		UseProgressBar = false
+		options.ProgressBar = false
+	}
+	// Status messages of the client (e.g: which repo is being checked) overwrite each other on the same line
+	options.Logf = func(format string, args ...interface{}) {
+		fmt.Printf("\033[2K\r"+format, args...)
 	}
+}
 
-	// The repos are a mess. So we need to do this. Sorry
-	arch := runtime.GOARCH + "_" + runtime.GOOS
-	switch arch {
-	case "amd64_linux":
-		ValidatedArch = [3]string{"x86_64_Linux", "x86_64", "x86_64-Linux"}
-	case "arm64_linux":
-		ValidatedArch = [3]string{"aarch64_arm64_Linux", "aarch64_arm64", "aarch64-Linux"}
-	case "arm64_android":
-		ValidatedArch = [3]string{"arm64_v8a_Android", "arm64_v8a_Android", "arm64-v8a-Android"}
-		//	case "amd64_windows": // not yet supported. Not sure if it will ever be.
-		//		ValidatedArch = [3]string{"x64_Windows", "x64_Windows", "AMD64-Windows_NT"}
-	default:
-		fmt.Println("Unsupported architecture:", arch)
-		os.Exit(1)
+// newClient creates a bigdl.Client out of the current options, exiting on failure (e.g: an unsupported --arch)
+func newClient() *bigdl.Client {
+	client, err := bigdl.New(options)
+	if err != nil {
+		errorOut("%v\n", err)
 	}
-	arch = ValidatedArch[0]
-	Repositories = append(Repositories, "https://bin.ajam.dev/"+arch+"/")
-	Repositories = append(Repositories, "https://bin.ajam.dev/"+arch+"/Baseutils/")
-	//Repositories = append(Repositories, "https://raw.githubusercontent.com/xplshn/Handyscripts/master/")
-	// Binaries that are available in the Repositories but aren't described in any MetadataURLs will not be updated, nor listed with `info` nor `list`
-	RNMetadataURL = "https://bin.ajam.dev/" + arch + "/METADATA.json" // RNMetadataURL is the file which contains a concatenation of all metadata in the different repos, this one also contains sha256 checksums
-	MetadataURLs = append(MetadataURLs, "https://bin.ajam.dev/"+arch+"/METADATA.json")
-	MetadataURLs = append(MetadataURLs, "https://bin.ajam.dev/"+arch+"/Baseutils/METADATA.json")
-	//MetadataURLs = append(MetadataURLs, "https://api.github.com/repos/xplshn/Handyscripts/contents")
+	return client
 }
 
 func printHelp() {
@@ -137,22 +86,38 @@ func printHelp() {
 Options:
  -h, --help       Show this help message
  -v, --version    Show the version number
+ --arch           Use the repos of another architecture (x86_64_Linux, aarch64_arm64_Linux, arm64_v8a_Android, riscv64_Linux, armv7_Linux)
+ --dest           Directory to install binaries to, instead of $INSTALL_DIR
+ --no-wait        Fail instead of waiting when another instance of bigdl is installing, removing or updating
 
 Commands:
  list             List all available binaries
- install, add     Install a binary
+ install, add     Install a binary. "src:dest" installs src under the name dest
  remove, del      Remove a binary
- update           Update binaries, by checking their SHA against the repo's SHA
+ update           Update binaries, by checking their SHA against the repo's SHA. --jobs sets how many at once
  run              Run a specified binary from cache
  info             Show information about a specific binary OR display installed binaries
  search           Search for a binary - (not all binaries have metadata. Use list to see all binaries)
+ history          Show what install, update and remove changed. Optionally, only for the given binary
+ undo             Revert the last install, update or remove. Run it again to revert the one before
+ verify           Check the installed binaries against their install-time hashes and the repos (ok, outdated, modified, unknown origin)
+ mirror           Download the binaries that match --filter (names, globs, topic:<topic>) and their metadata into --out, for serve. --url sets the address it will be served at
+ serve            Serve a mirror directory over HTTP (--dir <mirror>, --addr :8080), with the layout of the repos
+ doctor           Check the setup of bigdl ($PATH, installed binaries, temporary files, repos) and suggest fixes
  tldr             Equivalent to "run --transparent --verbose tlrc" as argument
+ self-update      Update bigdl itself to the latest release. Use --check to only check for it
 
 Variables:
  BIGDL_PRBAR      If present, and set to ZERO (0), the download progressbar will be disabled
  BIGDL_TRUNCATION If present, and set to ZERO (0), string truncation will be disabled
  BIGDL_ADDNEWLINE If present, and set to ONE  (1), truncated strings will always be on a new line
  BIGDL_CACHEDIR   If present, it must contain a valid directory
+ BIGDL_REPO_PRIORITY If present, a comma-separated list of repo names (e.g: Baseutils,Toolpacks) which sets the order in which repos are used
+ BIGDL_STATEDIR   If present, it must contain a valid directory. Records of installed binaries are kept there
+ BIGDL_MIRROR     If present, the URL of a mirror (see serve) to use instead of the repos. e.g: http://192.168.1.10:8080
+ BIGDL_SELFUPDATE_URL If present, self-update will look for new releases there (GitHub releases API format)
+ BIGDL_HOOKS      If present, the JSON file that declares the hooks run around installs, updates and removals. Defaults to ~/.config/bigdl/hooks.json
+ BIGDL_MEMFD      If present, and set to ONE  (1), "run" will execute binaries from memory (memfd_create)
  INSTALL_DIR      If present, it must contain a valid directory
 
 Examples:
@@ -160,16 +125,27 @@ Examples:
  bigdl install micro
  bigdl install lux kakoune aretext shfmt
  bigdl install --silent bed && echo "[bed] was installed to $INSTALL_DIR/bed"
+ bigdl install --entry rg ripgrep.tar.gz
+ bigdl install toybox/wget:twget
+ bigdl install Baseutils/ls
  bigdl del bed
  bigdl del orbiton tgpt lux
  bigdl info
  bigdl info jq
+ bigdl history jq
+ bigdl undo
+ bigdl doctor
+ bigdl verify jq
+ bigdl mirror --arch x86_64_Linux --filter btop,jq,topic:networking --out /srv/bigdl --url http://192.168.1.10:8080
+ bigdl serve --dir /srv/bigdl --addr :8080
  bigdl list --described
  bigdl tldr gum
  bigdl run --verbose curl -qsfSL "https://raw.githubusercontent.com/xplshn/bigdl/master/stubdl" | sh -
  bigdl run --silent elinks -no-home "https://fatbuffalo.neocities.org/def"
  bigdl run --transparent --silent micro ~/.profile
+ bigdl run --ephemeral jq --version
  bigdl run btop
+ bigdl --arch aarch64_arm64_Linux --dest ./rpi install btop
 
 Version: ` + VERSION
 
@@ -180,6 +156,10 @@ func main() {
 	errorOutInsufficientArgs := func() { errorOut("Error: Insufficient parameters\n") }
 	version := flag.Bool("v", false, "Show the version number")
 	versionLong := flag.Bool("version", false, "Show the version number")
+	arch := flag.String("arch", "", "Use the repos of another architecture")
+	dest := flag.String("dest", "", "Directory to install binaries to")
+	wait := flag.Bool("wait", true, "Wait for other instances of bigdl to finish")
+	noWait := flag.Bool("no-wait", false, "Fail instead of waiting for other instances of bigdl to finish")
 
 	flag.Usage = printHelp
 	flag.Parse()
@@ -192,9 +172,44 @@ func main() {
 		errorOut(" bigdl:%s\n", usagePage)
 	}
 
-	if err := os.MkdirAll(InstallDir, os.ModePerm); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to get user's Home directory. %v\n", err)
-		os.Exit(1)
+	// Ctrl-C (or SIGTERM) cancels every operation in flight: downloads stop, their temporary files are removed and nothing else gets installed
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	options.Arch = *arch
+	if *dest != "" {
+		options.InstallDir = *dest
+	}
+	if client := newClient(); client.IsForeignArch() {
+		// The cache and $PATH hold binaries for this machine, not for the requested architecture
+		options.UseCache = false
+		switch flag.Arg(0) {
+		case "run", "tldr":
+			errorOut("error: Binaries for %s can't be run on this machine\n", client.Arch()[0])
+		case "install", "add", "update":
+			if *dest == "" {
+				errorOut("error: --dest is required when installing binaries for another architecture (%s)\n", client.Arch()[0])
+			}
+		}
+	}
+
+	// run and tldr only use the cache, they must work where InstallDir can't be created (e.g: read-only home directories with --memfd)
+	if cmd := flag.Arg(0); cmd != "run" && cmd != "tldr" {
+		if err := os.MkdirAll(options.InstallDir, os.ModePerm); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Failed to get user's Home directory. %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// Commands that modify InstallDir or the state are serialized among concurrent runs
+	switch flag.Arg(0) {
+	case "install", "add", "remove", "del", "update", "undo":
+		loadHooks()
+		unlock, err := newClient().Lock(ctx, *wait && !*noWait)
+		if err != nil {
+			errorOut("%v\n", err)
+		}
+		defer unlock()
 	}
 
 	switch flag.Arg(0) {
@@ -204,17 +219,17 @@ func main() {
 			fmt.Println("Usage: bigdl find_url [binary]")
 			errorOutInsufficientArgs()
 		}
-		findURLCommand(binaryName)
+		findURLCommand(ctx, binaryName)
 	case "list":
-		if len(os.Args) == 3 {
-			if os.Args[2] == "--described" || os.Args[2] == "-d" {
+		if flag.NArg() == 2 {
+			if flag.Arg(1) == "--described" || flag.Arg(1) == "-d" {
 				// Call fSearch with an empty query and a large limit to list all described binaries
-				fSearch("", 99999)
+				fSearch(ctx, "", 99999)
 			} else {
 				errorOut("bigdl: Unknown command.\n")
 			}
 		} else {
-			binaries, err := listBinaries()
+			binaries, err := newClient().List(ctx)
 			if err != nil {
 				fmt.Println("Error listing binaries:", err)
 				os.Exit(1)
@@ -225,21 +240,30 @@ func main() {
 		}
 	case "install", "add":
 		if flag.NArg() < 2 {
-			fmt.Printf("Usage: bigdl %s <--silent> [binar|y|ies]\n", flag.Arg(0))
+			fmt.Printf("Usage: bigdl %s <--silent> <--no-extras> <--entry [file,...]> [binar|y|ies]\n", flag.Arg(0))
 			os.Exit(1)
 		}
 
-		// Join the binary names into a single string separated by spaces
-		binaries := strings.Join(flag.Args()[1:], " ")
+		var binaries []string
 		silent := false
-		if flag.Arg(1) == "--silent" {
-			silent = true
-			// Skip the "--silent" flag when joining the binary names
-			binaries = strings.Join(flag.Args()[2:], " ")
+		for i := 1; i < flag.NArg(); i++ {
+			switch flag.Arg(i) {
+			case "--silent":
+				silent = true
+			case "--no-extras":
+				options.InstallExtras = false
+			case "--entry":
+				i++
+				if flag.Arg(i) == "" {
+					errorOut("Error: Missing '--entry' value.\n")
+				}
+				options.ArchiveEntries = append(options.ArchiveEntries, strings.Split(flag.Arg(i), ",")...)
+			default:
+				binaries = append(binaries, flag.Arg(i))
+			}
 		}
 
-		err := installCommand(silent, binaries)
-		if err != nil {
+		if err := installCommand(ctx, silent, binaries); err != nil {
 			fmt.Printf("Installation failed: %v\n", err)
 			os.Exit(1)
 		}
@@ -248,68 +272,36 @@ func main() {
 			fmt.Printf("Usage: bigdl %s [binar|y|ies]\n", flag.Arg(0))
 			errorOutInsufficientArgs()
 		}
-		remove(flag.Args()[1:])
+		remove(ctx, flag.Args()[1:])
 	case "run":
 		if flag.NArg() < 2 {
-			fmt.Println("Usage: bigdl run <--verbose, --silent, --transparent> [binary] <args>")
+			fmt.Println("Usage: bigdl run <--verbose, --silent, --transparent, --ephemeral, --memfd> [binary] <args>")
 			errorOutInsufficientArgs()
 		}
-		RunFromCache(flag.Arg(1), flag.Args()[2:])
+		RunFromCache(ctx, flag.Arg(1), flag.Args()[2:])
 	case "tldr":
 		args := append([]string{"--transparent", "--verbose", "tlrc"}, flag.Args()[1:]...) // UGLY!
-		RunFromCache(args[0], args[1:])
+		RunFromCache(ctx, args[0], args[1:])
 	case "info":
-		binaryName := flag.Arg(1)
-		if len(os.Args) < 3 {
-			installedPrograms, err := validateProgramsFrom(InstallDir, nil)
-			if err != nil {
-				fmt.Println("Error validating programs:", err)
-				return
-			}
-			for _, program := range installedPrograms {
-				fmt.Println(program)
-			}
+		if flag.NArg() < 2 {
+			showInstalled(ctx)
 		} else {
-			binaryInfo, err := getBinaryInfo(binaryName)
-			if err != nil {
-				errorOut("%v\n", err)
-			}
-			fmt.Printf("Name: %s\n", binaryInfo.Name)
-			if binaryInfo.Description != "" {
-				fmt.Printf("Description: %s\n", binaryInfo.Description)
-			}
-			if binaryInfo.Repo != "" {
-				fmt.Printf("Repo: %s\n", binaryInfo.Repo)
-			}
-			if binaryInfo.Updated != "" {
-				fmt.Printf("Updated: %s\n", binaryInfo.Updated)
-			}
-			if binaryInfo.Version != "" {
-				fmt.Printf("Version: %s\n", binaryInfo.Version)
-			}
-			if binaryInfo.Size != "" {
-				fmt.Printf("Size: %s\n", binaryInfo.Size)
-			}
-			if binaryInfo.Source != "" { // if binaryInfo.Extras != "" {
-				fmt.Printf("Source: %s\n", binaryInfo.Source)
-			}
-			if binaryInfo.SHA256 != "" {
-				fmt.Printf("SHA256: %s\n", binaryInfo.SHA256)
-			}
+			showInfo(ctx, flag.Arg(1))
 		}
 	case "search":
 		limit := 90
-		queryIndex := 2
+		queryIndex := 1
+		args := flag.Args()
 
-		if len(os.Args) < queryIndex+1 {
+		if len(args) < queryIndex+1 {
 			fmt.Println("Usage: bigdl search <--limit||-l [int]> [query]")
 			os.Exit(1)
 		}
 
-		if len(os.Args) > 2 && os.Args[queryIndex] == "--limit" || os.Args[queryIndex] == "-l" {
-			if len(os.Args) > queryIndex+1 {
+		if len(args) > 1 && args[queryIndex] == "--limit" || args[queryIndex] == "-l" {
+			if len(args) > queryIndex+1 {
 				var err error
-				limit, err = strconv.Atoi(os.Args[queryIndex+1])
+				limit, err = strconv.Atoi(args[queryIndex+1])
 				if err != nil {
 					errorOut("Error: 'limit' value is not an int.\n")
 				}
@@ -319,14 +311,90 @@ func main() {
 			}
 		}
 
-		query := os.Args[queryIndex]
-		fSearch(query, limit)
+		if len(args) < queryIndex+1 {
+			errorOut("Error: Missing query.\n")
+		}
+		query := args[queryIndex]
+		fSearch(ctx, query, limit)
+	case "history":
+		if flag.NArg() > 2 {
+			fmt.Println("Usage: bigdl history <binary>")
+			os.Exit(1)
+		}
+		showHistory(flag.Arg(1))
+	case "undo":
+		undo(ctx)
+	case "doctor":
+		doctor(ctx)
+	case "verify":
+		var binaries []string
+		if flag.NArg() > 1 {
+			binaries = flag.Args()[1:]
+		}
+		verify(ctx, binaries)
+	case "mirror":
+		var filters []string
+		outDir, baseURL := "", "http://localhost:8080"
+		for i := 1; i < flag.NArg(); i++ {
+			switch flag.Arg(i) {
+			case "--arch":
+				i++
+				options.Arch = flag.Arg(i)
+			case "--filter":
+				i++
+				filters = append(filters, strings.Split(flag.Arg(i), ",")...)
+			case "--out":
+				i++
+				outDir = flag.Arg(i)
+			case "--url":
+				i++
+				baseURL = flag.Arg(i)
+			default:
+				errorOut("Usage: bigdl mirror <--arch x86_64_Linux> <--filter [names|globs|topic:<topic>]> --out <dir> <--url http://localhost:8080>\n")
+			}
+		}
+		if outDir == "" || baseURL == "" {
+			errorOut("Usage: bigdl mirror <--arch x86_64_Linux> <--filter [names|globs|topic:<topic>]> --out <dir> <--url http://localhost:8080>\n")
+		}
+		mirror(ctx, outDir, baseURL, filters)
+	case "serve":
+		dir, addr := "", ":8080"
+		for i := 1; i < flag.NArg(); i++ {
+			switch flag.Arg(i) {
+			case "--dir":
+				i++
+				dir = flag.Arg(i)
+			case "--addr":
+				i++
+				addr = flag.Arg(i)
+			default:
+				errorOut("Usage: bigdl serve --dir <mirror> <--addr :8080>\n")
+			}
+		}
+		if dir == "" || addr == "" {
+			errorOut("Usage: bigdl serve --dir <mirror> <--addr :8080>\n")
+		}
+		serve(ctx, dir, addr)
+	case "self-update":
+		checkOnly := flag.Arg(1) == "--check"
+		if err := selfUpdate(ctx, checkOnly); err != nil {
+			errorOut("%v\n", err)
+		}
 	case "update":
 		var programsToUpdate []string
-		if len(os.Args) > 2 {
-			programsToUpdate = os.Args[2:]
+		jobs := runtime.NumCPU()
+		for i := 1; i < flag.NArg(); i++ {
+			if flag.Arg(i) == "--jobs" || flag.Arg(i) == "-j" {
+				i++
+				var err error
+				if jobs, err = strconv.Atoi(flag.Arg(i)); err != nil || jobs < 1 {
+					errorOut("Error: '--jobs' value must be a positive int.\n")
+				}
+				continue
+			}
+			programsToUpdate = append(programsToUpdate, flag.Arg(i))
 		}
-		update(programsToUpdate)
+		update(ctx, programsToUpdate, jobs)
 	default:
 		errorOut("bigdl: Unknown command.\n")
 	}
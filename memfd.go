@@ -0,0 +1,66 @@
+// memfd.go // This file implements running binaries straight from memory, for read-only and noexec filesystems //>
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/xplshn/bigdl/pkg/bigdl"
+	"golang.org/x/sys/unix"
+)
+
+// fetchBinaryToMemfd fetches a binary from the given URL into an anonymous, memory-backed file created with memfd_create. Nothing is written to disk.
+func fetchBinaryToMemfd(ctx context.Context, client *bigdl.Client, url, binaryName string) (*os.File, error) {
+	// MFD_CLOEXEC is deliberately not set: scripts are executed by an interpreter that has to open /proc/self/fd/N after the exec
+	fd, err := unix.MemfdCreate(filepath.Base(binaryName), 0)
+	if err != nil {
+		return nil, fmt.Errorf("memfd_create failed: %v", err)
+	}
+	memFile := os.NewFile(uintptr(fd), filepath.Base(binaryName))
+
+	if err := client.DownloadTo(ctx, url, memFile); err != nil {
+		memFile.Close()
+		return nil, err
+	}
+
+	fmt.Print("\033[2K\r") // Clean the line
+	return memFile, nil
+}
+
+// memfdPath returns the path through which the memfd can be opened or executed.
+func memfdPath(memFile *os.File) string {
+	return fmt.Sprintf("/proc/self/fd/%d", memFile.Fd())
+}
+
+// RunFromMemfd fetches the binary into memory, verifies it and runs it via /proc/self/fd/N.
+func RunFromMemfd(ctx context.Context, binaryName string, args []string) {
+	if verboseMode {
+		fmt.Printf("Fetching '%s' into memory...\n", binaryName)
+	}
+
+	client := newClient()
+	url, err := client.FindURL(ctx, binaryName)
+	if err != nil {
+		errorOut("%v\n", err)
+	}
+
+	memFile, err := fetchBinaryToMemfd(ctx, client, url, binaryName)
+	if err != nil {
+		errorOut("%v\n", err)
+	}
+	defer memFile.Close()
+
+	binaryPath := memfdPath(memFile)
+	if err := verifyBinary(ctx, client, binaryName, binaryPath); err != nil {
+		errorOut("%v\n", err)
+	}
+	warnings, err := client.CheckBinary(binaryName, binaryPath)
+	if err != nil {
+		errorOut("%v\n", err)
+	}
+	printWarnings(warnings)
+
+	runBinary(binaryPath, args, verboseMode)
+}
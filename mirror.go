@@ -0,0 +1,56 @@
+// mirror.go // This file implements the "mirror" command //>
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/xplshn/bigdl/pkg/bigdl"
+)
+
+// mirror replicates the binaries of the repos that match the filters into outDir, whose metadata will point to baseURL. See bigdl.Client.Mirror
+func mirror(ctx context.Context, outDir, baseURL string, filters []string) {
+	var (
+		unchanged, downloaded, failed int
+		errorMessages                 []string
+	)
+
+	progress := func(done, total int, result bigdl.MirrorResult) {
+		switch result.Status {
+		case bigdl.MirrorUnchanged:
+			unchanged++
+		case bigdl.MirrorDownloaded:
+			downloaded++
+		case bigdl.MirrorFailed:
+			failed++
+			errorMessages = append(errorMessages, fmt.Sprintf("%s: %v", result.Name, result.Err))
+		}
+		truncatePrintf("\033[2K\r<%d/%d> | %s", done, total, result.Message)
+	}
+
+	results, err := newClient().Mirror(ctx, outDir, bigdl.MirrorOptions{Filters: filters, BaseURL: baseURL, Progress: progress})
+	if err != nil && results == nil {
+		errorOut("%v\n", err)
+	}
+	if len(results) == 0 {
+		errorOut("No binary matches the filters\n")
+	}
+
+	finalCounts := fmt.Sprintf("\033[2K\rUnchanged: %d\tDownloaded: %d", unchanged, downloaded)
+	if failed > 0 {
+		finalCounts += fmt.Sprintf("\tErrors: %d", failed)
+	}
+	if ctx.Err() != nil {
+		finalCounts += "\tInterrupted"
+	}
+	fmt.Println(finalCounts)
+	for _, errorMessage := range errorMessages {
+		fmt.Println(errorMessage)
+	}
+	if err != nil && ctx.Err() == nil {
+		errorOut("%v\n", err)
+	}
+	if failed > 0 {
+		errorOut("%d binar(y|ies) could not be mirrored\n", failed)
+	}
+}
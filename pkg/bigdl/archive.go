@@ -0,0 +1,283 @@
+// archive.go // This file implements installing binaries out of archives (.tar, .tar.gz, .tar.bz2, .zip, .gz, .bz2) //>
+package bigdl
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// archiveExtensions are the archive formats bigdl can extract. Compound extensions go first
+var archiveExtensions = []string{".tar.gz", ".tgz", ".tar.bz2", ".tbz2", ".tar", ".zip", ".gz", ".bz2"}
+
+// archiveExtension returns the archive extension of the file name, or an empty string if it isn't an archive.
+func archiveExtension(name string) string {
+	lowerName := strings.ToLower(name)
+	for _, ext := range archiveExtensions {
+		if strings.HasSuffix(lowerName, ext) {
+			return ext
+		}
+	}
+	return ""
+}
+
+// archiveEntry is a file found inside of an archive that may be installed
+type archiveEntry struct {
+	path       string
+	executable bool
+	open       func() (io.Reader, error)
+}
+
+// installFromArchive downloads the archive at url, verifies it and installs the selected executables it contains (mode.entries) to InstallDir.
+func (c *Client) installFromArchive(ctx context.Context, archiveName string, mode installMode) ([]InstallResult, error) {
+	url, err := c.FindURL(ctx, archiveName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(c.opts.CacheDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %v", err)
+	}
+
+	archiveFile, err := os.CreateTemp(c.opts.CacheDir, filepath.Base(archiveName)+".*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %v", err)
+	}
+	defer os.Remove(archiveFile.Name())
+	defer archiveFile.Close()
+
+	if err := c.downloadTo(ctx, url, archiveFile, mode.progress); err != nil {
+		return nil, err
+	}
+	verified, err := c.VerifyFile(ctx, archiveName, archiveFile.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	// Updates compare these to the repo's, to know whether the binaries extracted from it changed
+	archiveChecksum := &Checksum{SHA256: fileSHA256(archiveFile.Name())}
+	archiveChecksum.B3SUM, _ = B3SUMFile(archiveFile.Name())
+
+	entries, err := readArchive(archiveFile, archiveName, mode.entries)
+	if err != nil {
+		return nil, fmt.Errorf("error: could not read %s: %v", archiveName, err)
+	}
+
+	var results []InstallResult
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+
+		result := InstallResult{Name: archiveName, Path: filepath.Join(c.opts.InstallDir, filepath.Base(entry.path)), Archive: archiveName}
+		if !verified {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("No b3sum nor SHA256 available for '%s', it could not be verified", archiveName))
+		}
+		if mode.hooks {
+			result.HookErrors = c.runHooks(ctx, PreInstall, c.remoteTarget(ctx, archiveName, result.Path))
+		}
+		oldSHA256, oldRecord := c.backup(result.Path), c.installedRecord(result.Path)
+		warnings, err := c.installArchiveEntry(entry, result.Path)
+		mode.recordChange(ctx, c, archiveName, result.Path, oldSHA256, oldRecord, err)
+		if err != nil {
+			return results, err
+		}
+		result.Warnings = append(result.Warnings, warnings...)
+		if err := c.recordInstall(result.Path, InstalledBinary{Name: archiveName, Archive: archiveChecksum}); err != nil {
+			return results, fmt.Errorf("failed to record the installation of '%s': %v", entry.path, err)
+		}
+		if mode.hooks {
+			result.HookErrors = append(result.HookErrors, c.runHooks(ctx, PostInstall, c.installedTarget(ctx, archiveName, result.Path))...)
+		}
+		results = append(results, result)
+	}
+
+	if len(results) == 0 {
+		if len(mode.entries) > 0 {
+			return nil, fmt.Errorf("error: %s contains none of the requested entries (%s)", archiveName, strings.Join(mode.entries, ", "))
+		}
+		return nil, fmt.Errorf("error: %s contains no executables. Use --entry to pick the files to install", archiveName)
+	}
+	return results, nil
+}
+
+// installArchiveEntry extracts the entry to a temporary file, inspects it and moves it to installPath.
+func (c *Client) installArchiveEntry(entry archiveEntry, installPath string) ([]string, error) {
+	reader, err := entry.open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract %s: %v", entry.path, err)
+	}
+
+	tempFile, err := os.CreateTemp(c.opts.CacheDir, filepath.Base(entry.path)+".*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %v", err)
+	}
+	defer os.Remove(tempFile.Name())
+
+	if _, err := io.Copy(tempFile, reader); err != nil {
+		tempFile.Close()
+		return nil, fmt.Errorf("failed to extract %s: %v", entry.path, err)
+	}
+	if err := tempFile.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close temporary file: %v", err)
+	}
+
+	warnings, err := c.CheckBinary(filepath.Base(entry.path), tempFile.Name())
+	if err != nil {
+		return nil, err
+	}
+	return warnings, c.moveExecutable(tempFile.Name(), installPath)
+}
+
+// selectEntry reports whether the file at entryPath of an archive is to be installed: it is one of the selected entries (by path or by name), or an executable when none is selected
+func selectEntry(selected []string, entryPath string, executable func() bool) bool {
+	if len(selected) > 0 {
+		return contains(selected, entryPath) || contains(selected, filepath.Base(entryPath))
+	}
+	return executable()
+}
+
+// readArchive returns the regular files of the archive that are to be installed (see selectEntry). Entries of tar archives can only be opened in order, which is how installFromArchive uses them.
+// Since they are all installed to the same directory, two entries with the same name are an error.
+func readArchive(archiveFile *os.File, archiveName string, selected []string) ([]archiveEntry, error) {
+	if _, err := archiveFile.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	var entries []archiveEntry
+	ext := archiveExtension(archiveName)
+	switch ext {
+	case ".zip":
+		info, err := archiveFile.Stat()
+		if err != nil {
+			return nil, err
+		}
+		zipReader, err := zip.NewReader(archiveFile, info.Size())
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range zipReader.File {
+			if !file.Mode().IsRegular() {
+				continue
+			}
+			file := file
+			if !selectEntry(selected, file.Name, func() bool { return file.Mode().Perm()&0o111 != 0 || hasExecutableMagic(file.Open) }) {
+				continue
+			}
+			entries = append(entries, archiveEntry{
+				path:       file.Name,
+				executable: true,
+				open: func() (io.Reader, error) {
+					return file.Open()
+				},
+			})
+		}
+	case ".tar", ".tar.gz", ".tgz", ".tar.bz2", ".tbz2":
+		reader, err := decompressor(ext, archiveFile)
+		if err != nil {
+			return nil, err
+		}
+		if entries, err = readTar(tar.NewReader(reader), selected); err != nil {
+			return nil, err
+		}
+	default: // A single compressed file, named like the archive minus its extension
+		reader, err := decompressor(ext, archiveFile)
+		if err != nil {
+			return nil, err
+		}
+		name := filepath.Base(archiveName)
+		name = name[:len(name)-len(ext)]
+		if selectEntry(selected, name, func() bool { return true }) {
+			entries = append(entries, archiveEntry{
+				path:       name,
+				executable: true,
+				open:       func() (io.Reader, error) { return reader, nil },
+			})
+		}
+	}
+
+	seen := make(map[string]string)
+	for _, entry := range entries {
+		if other, duplicated := seen[filepath.Base(entry.path)]; duplicated {
+			return nil, fmt.Errorf("'%s' and '%s' would both be installed as '%s', use --entry to pick one by its path", other, entry.path, filepath.Base(entry.path))
+		}
+		seen[filepath.Base(entry.path)] = entry.path
+	}
+	return entries, nil
+}
+
+// readTar loads the regular files of a tar archive that are to be installed (see selectEntry) in memory, since a tar stream can't be rewound. The others are skipped without being read.
+func readTar(tarReader *tar.Reader, selected []string) ([]archiveEntry, error) {
+	var entries []archiveEntry
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		// Without selected entries, files are only read as far as their magic number unless they are executables
+		magic := make([]byte, 4)
+		n, _ := io.ReadFull(tarReader, magic)
+		magic = magic[:n]
+		if !selectEntry(selected, header.Name, func() bool { return header.FileInfo().Mode().Perm()&0o111 != 0 || isExecutableMagic(magic) }) {
+			continue
+		}
+
+		rest, err := io.ReadAll(tarReader)
+		if err != nil {
+			return nil, err
+		}
+		content := append(magic, rest...)
+		entries = append(entries, archiveEntry{
+			path:       header.Name,
+			executable: true,
+			open: func() (io.Reader, error) {
+				return bytes.NewReader(content), nil
+			},
+		})
+	}
+	return entries, nil
+}
+
+// decompressor wraps the reader with the decompressor that corresponds to the archive extension.
+func decompressor(ext string, reader io.Reader) (io.Reader, error) {
+	switch ext {
+	case ".tar.gz", ".tgz", ".gz":
+		return gzip.NewReader(reader)
+	case ".tar.bz2", ".tbz2", ".bz2":
+		return bzip2.NewReader(reader), nil
+	}
+	return reader, nil
+}
+
+// hasExecutableMagic opens a file and checks if it starts like an ELF or a script.
+func hasExecutableMagic(open func() (io.ReadCloser, error)) bool {
+	reader, err := open()
+	if err != nil {
+		return false
+	}
+	defer reader.Close()
+
+	header := make([]byte, 4)
+	n, _ := io.ReadFull(reader, header)
+	return isExecutableMagic(header[:n])
+}
+
+// isExecutableMagic checks if the content starts with the ELF magic or a shebang.
+func isExecutableMagic(content []byte) bool {
+	return bytes.HasPrefix(content, []byte("\x7fELF")) || bytes.HasPrefix(content, []byte("#!"))
+}
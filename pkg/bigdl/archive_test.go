@@ -0,0 +1,110 @@
+package bigdl
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// tarGz returns a .tar.gz archive of the files, given as path and content pairs
+func tarGz(t *testing.T, files ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for i := 0; i < len(files); i += 2 {
+		header := &tar.Header{Name: files[i], Mode: 0o644, Size: int64(len(files[i+1])), Typeflag: tar.TypeReg}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tarWriter.Write([]byte(files[i+1])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// archiveFile writes the archive to a temporary file named archiveName
+func archiveFile(t *testing.T, archiveName string, content []byte) *os.File {
+	t.Helper()
+	filePath := filepath.Join(t.TempDir(), archiveName)
+	if err := os.WriteFile(filePath, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { file.Close() })
+	return file
+}
+
+func TestReadArchive(t *testing.T) {
+	archive := tarGz(t,
+		"rg-1/rg", "\x7fELF binary",
+		"rg-1/complete/rg.bash", "#!/bin/bash",
+		"rg-1/README.md", "docs",
+	)
+	tests := []struct {
+		selected []string
+		want     map[string]string
+	}{
+		// Without selected entries, only executables are read
+		{nil, map[string]string{"rg-1/rg": "\x7fELF binary", "rg-1/complete/rg.bash": "#!/bin/bash"}},
+		// Entries are selected by path or by name, executables or not
+		{[]string{"README.md"}, map[string]string{"rg-1/README.md": "docs"}},
+		{[]string{"rg-1/rg", "missing"}, map[string]string{"rg-1/rg": "\x7fELF binary"}},
+		{[]string{"missing"}, map[string]string{}},
+	}
+	for _, test := range tests {
+		entries, err := readArchive(archiveFile(t, "rg.tar.gz", archive), "rg.tar.gz", test.selected)
+		if err != nil {
+			t.Fatalf("readArchive(%v): %v", test.selected, err)
+		}
+		got := make(map[string]string)
+		for _, entry := range entries {
+			reader, err := entry.open()
+			if err != nil {
+				t.Fatal(err)
+			}
+			content, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got[entry.path] = string(content)
+		}
+		if len(got) != len(test.want) {
+			t.Errorf("readArchive(%v) = %v, want %v", test.selected, got, test.want)
+			continue
+		}
+		for entryPath, content := range test.want {
+			if got[entryPath] != content {
+				t.Errorf("readArchive(%v) = %v, want %v", test.selected, got, test.want)
+				break
+			}
+		}
+	}
+}
+
+func TestReadArchiveDuplicateNames(t *testing.T) {
+	archive := tarGz(t, "amd64/tool", "#!/bin/sh", "arm64/tool", "#!/bin/sh", "arm64/other", "#!/bin/sh")
+
+	if _, err := readArchive(archiveFile(t, "tools.tar.gz", archive), "tools.tar.gz", nil); err == nil {
+		t.Error("two entries named tool should be an error")
+	}
+	// Picking one of them by its path resolves the conflict
+	entries, err := readArchive(archiveFile(t, "tools.tar.gz", archive), "tools.tar.gz", []string{"arm64/tool"})
+	if err != nil || len(entries) != 1 || entries[0].path != "arm64/tool" {
+		t.Errorf("readArchive(arm64/tool) = %+v, %v", entries, err)
+	}
+}
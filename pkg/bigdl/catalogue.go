@@ -0,0 +1,321 @@
+// catalogue.go // This file implements the catalogue of binaries, which info, search, list and update use //>
+package bigdl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/goccy/go-json"
+)
+
+// BinaryInfo describes a binary in the repos. Its fields match the entries of METADATA.json (see misc/cmd/modMetadata)
+type BinaryInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Source      string `json:"download_url"`
+	Size        string `json:"size"`
+	B3SUM       string `json:"b3sum"`
+	SHA256      string `json:"sha256"`
+	ModTime     string `json:"build_date"`
+	Repo        string `json:"repo_url"`
+	Author      string `json:"repo_author"`
+	RepoInfo    string `json:"repo_info"`
+	Updated     string `json:"repo_updated"`
+	Released    string `json:"repo_released"`
+	Version     string `json:"repo_version"`
+	Stars       string `json:"repo_stars"`
+	Language    string `json:"repo_language"`
+	License     string `json:"repo_license"`
+	Topics      string `json:"repo_topics"`
+	WebURL      string `json:"web_url"`
+	Extras      string `json:"extra_bins"`
+}
+
+// Catalogue holds the binaries described by a metadata file, indexed by name and by repository-qualified name ("Baseutils/ls")
+type Catalogue struct {
+	Binaries []BinaryInfo
+	// Repos holds the index in Repositories() of the repo that provides each of the Binaries
+	Repos []int
+	index map[string]int
+}
+
+// Lookup returns the binary with the given name, or repository-qualified name.
+func (cat *Catalogue) Lookup(binaryName string) (BinaryInfo, bool) {
+	if i, found := cat.index[binaryName]; found {
+		return cat.Binaries[i], true
+	}
+	return BinaryInfo{}, false
+}
+
+// ExcludedFileTypes are file types that shall not appear in Lists nor in the Search Results
+var ExcludedFileTypes = map[string]struct{}{
+	".7z":   {},
+	".json": {},
+	".md":   {},
+	".txt":  {},
+	".cfg":  {},
+	".dir":  {},
+	".test": {},
+}
+
+// ExcludedFileNames are file names that shall not appear in Lists nor in the Search Results
+var ExcludedFileNames = map[string]struct{}{
+	"TEST":                     {},
+	"LICENSE":                  {},
+	"experimentalBinaries_dir": {},
+	"bundles_dir":              {},
+	"blobs_dir":                {},
+	"robotstxt":                {},
+	"bdl.sh":                   {},
+	// Because the repo contains duplicated files. And I do not manage the repo nor plan to implement sha256 filtering :
+	"uroot":             {},
+	"uroot-busybox":     {},
+	"gobusybox":         {},
+	"sysinfo-collector": {},
+	"neofetch":          {},
+	"sh":                {}, // Because in the repo, it is a duplicate of bash and not a POSIX implementation nor the original Thompshon Shell
+}
+
+// IsExcluded reports whether the binary has an excluded file type or name
+func IsExcluded(binaryName string) bool {
+	if _, excluded := ExcludedFileTypes[strings.ToLower(filepath.Ext(binaryName))]; excluded {
+		return true
+	}
+	_, excludedName := ExcludedFileNames[filepath.Base(binaryName)]
+	return excludedName
+}
+
+// FetchJSON fetches the JSON at url with Options.HTTPClient and decodes it into v. Responses are cached for the lifetime of the Client.
+func (c *Client) FetchJSON(ctx context.Context, url string, v interface{}) error {
+	c.fetchedJSONMutex.Lock()
+	body, cached := c.fetchedJSON[url]
+	c.fetchedJSONMutex.Unlock()
+
+	if !cached {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return fmt.Errorf("error creating request: %v", err)
+		}
+		response, err := c.opts.HTTPClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("error fetching from %s: %v", url, err)
+		}
+		defer response.Body.Close()
+		if response.StatusCode != http.StatusOK {
+			return fmt.Errorf("error fetching from %s: HTTP status code: %d", url, response.StatusCode)
+		}
+
+		body, err = io.ReadAll(response.Body)
+		if err != nil {
+			return fmt.Errorf("error reading from %s: %v", url, err)
+		}
+
+		c.fetchedJSONMutex.Lock()
+		c.fetchedJSON[url] = body
+		c.fetchedJSONMutex.Unlock()
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("error decoding from %s: %v", url, err)
+	}
+
+	return nil
+}
+
+// loadCatalogue fetches and indexes the metadata at metadataURL, once per Client. Binaries whose download_url doesn't belong to any repo are attributed to the repo that serves the metadata.
+func (c *Client) loadCatalogue(ctx context.Context, metadataURL string) (*Catalogue, error) {
+	c.cataloguesMutex.Lock()
+	defer c.cataloguesMutex.Unlock()
+
+	if catalogue, loaded := c.catalogues[metadataURL]; loaded {
+		return catalogue, nil
+	}
+
+	catalogue := &Catalogue{}
+	if err := c.FetchJSON(ctx, metadataURL, &catalogue.Binaries); err != nil {
+		return nil, err
+	}
+
+	catalogue.Repos = make([]int, len(catalogue.Binaries))
+	catalogue.index = make(map[string]int, len(catalogue.Binaries))
+	defaultRepo := c.metadataRepo(metadataURL)
+	for i, binInfo := range catalogue.Binaries {
+		repo := c.repoOf(binInfo.Source)
+		if repo == -1 {
+			repo = defaultRepo
+		}
+		catalogue.Repos[i] = repo
+		if binInfo.Name == "" {
+			continue
+		}
+
+		// Unqualified names resolve to the repo with the highest priority
+		if _, indexed := catalogue.index[c.QualifiedName(repo, binInfo.Name)]; !indexed {
+			catalogue.index[c.QualifiedName(repo, binInfo.Name)] = i
+		}
+		if best, indexed := catalogue.index[binInfo.Name]; !indexed || repo < catalogue.Repos[best] {
+			catalogue.index[binInfo.Name] = i
+		}
+	}
+
+	c.catalogues[metadataURL] = catalogue
+	return catalogue, nil
+}
+
+// Catalogue returns the catalogue of every repo (see IndexURL).
+func (c *Client) Catalogue(ctx context.Context) (*Catalogue, error) {
+	return c.loadCatalogue(ctx, c.rnMetadataURL)
+}
+
+// Info returns the metadata of the binary. Repository-qualified names ("Baseutils/ls") are looked up in that repo only.
+func (c *Client) Info(ctx context.Context, binaryName string) (*BinaryInfo, error) {
+	binInfo, found, err := c.lookup(ctx, binaryName)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("error: info for the requested binary ('%s') not found in the metadata.json file", binaryName)
+	}
+	return binInfo, nil
+}
+
+// lookup is Info, but a binary that no metadata describes isn't an error. Only failing to fetch the metadata is.
+func (c *Client) lookup(ctx context.Context, binaryName string) (*BinaryInfo, bool, error) {
+	catalogue, err := c.Catalogue(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	if binInfo, found := catalogue.Lookup(binaryName); found {
+		return &binInfo, true, nil
+	}
+
+	// The index describes every repo, but the repo's own metadata is checked too when a specific repo was requested
+	if repoIndex, name := c.SplitRepoName(binaryName); repoIndex != -1 && c.repos[repoIndex].MetadataURL != c.rnMetadataURL {
+		catalogue, err := c.loadCatalogue(ctx, c.repos[repoIndex].MetadataURL)
+		if err != nil {
+			return nil, false, err
+		}
+		if binInfo, found := catalogue.Lookup(name); found {
+			return &binInfo, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+// List returns the binaries of every repo, prefixed by the name of their repo ("Baseutils/ls"), in order of priority. Excluded file types and names are left out.
+func (c *Client) List(ctx context.Context) ([]string, error) {
+	var allBinaries []string
+	// Fetch binaries from each metadata URL
+	for i, repo := range c.repos {
+		catalogue, err := c.loadCatalogue(ctx, repo.MetadataURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch metadata from %s: %v", repo.MetadataURL, err)
+		}
+
+		for j, binary := range catalogue.Binaries {
+			// The metadata of the first repo describes every repo, each binary is listed with its own
+			if catalogue.Repos[j] != i || binary.Name == "" || IsExcluded(binary.Name) {
+				continue
+			}
+			allBinaries = append(allBinaries, c.QualifiedName(i, binary.Name))
+		}
+	}
+
+	return removeDuplicates(allBinaries), nil
+}
+
+// ListNames returns the names of the binaries available in the repos, without their repo and without duplicates.
+func (c *Client) ListNames(ctx context.Context) ([]string, error) {
+	qualifiedBinaries, err := c.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var allBinaries []string
+	for _, binary := range qualifiedBinaries {
+		_, name := c.SplitRepoName(binary)
+		allBinaries = append(allBinaries, name)
+	}
+
+	return removeDuplicates(allBinaries), nil
+}
+
+// SearchResult is a binary matched by Search
+type SearchResult struct {
+	Name        string // Repository-qualified, e.g: "Baseutils/ls"
+	Description string
+	InstallPath string // Where the binary would be installed
+}
+
+// Search returns the described binaries whose name or description contains the search term, sorted by name.
+func (c *Client) Search(ctx context.Context, searchTerm string) ([]SearchResult, error) {
+	catalogue, err := c.Catalogue(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	searchTerm = strings.ToLower(searchTerm)
+	seen := make(map[string]struct{})
+	var results []SearchResult
+	for i, binary := range catalogue.Binaries {
+		if !strings.Contains(strings.ToLower(binary.Name), searchTerm) && !strings.Contains(strings.ToLower(binary.Description), searchTerm) {
+			continue
+		}
+		if IsExcluded(binary.Name) || binary.Description == "" {
+			continue
+		}
+		name := c.QualifiedName(catalogue.Repos[i], binary.Name)
+		if _, duplicated := seen[name+binary.Description]; duplicated {
+			continue
+		}
+		seen[name+binary.Description] = struct{}{}
+		results = append(results, SearchResult{
+			Name:        name,
+			Description: binary.Description,
+			InstallPath: filepath.Join(c.opts.InstallDir, filepath.Base(binary.Name)),
+		})
+	}
+
+	sortResults(results)
+	return results, nil
+}
+
+// FindURL returns the URL of the binary. The download_url of the metadata is used when available, otherwise the repos are probed with HEAD requests.
+// Repository-qualified names ("Baseutils/ls") are only looked up in that repo. Otherwise, the repos are checked in order of priority.
+func (c *Client) FindURL(ctx context.Context, binaryName string) (string, error) {
+	// The metadata is fetched once per Client, and already knows where described binaries are, which saves a HEAD request per repo
+	if binaryInfo, err := c.Info(ctx, binaryName); err == nil && binaryInfo.Source != "" {
+		return binaryInfo.Source, nil
+	}
+
+	repositories := c.repos
+	if repoIndex, name := c.SplitRepoName(binaryName); repoIndex != -1 {
+		repositories = c.repos[repoIndex : repoIndex+1]
+		binaryName = name
+	}
+
+	for i, repo := range repositories {
+		url := repo.URL + binaryName
+		c.logf("<%d/%d> | Working: Checking if \"%s\" is in the repos.", i+1, len(repositories), binaryName)
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+		if err != nil {
+			return "", err
+		}
+		resp, err := c.opts.HTTPClient.Do(req)
+		if err != nil {
+			return "", err
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode == http.StatusOK {
+			c.logf("<%d/%d> | Found \"%s\" at %s", i+1, len(repositories), binaryName, repo.URL)
+			return url, nil
+		}
+	}
+
+	return "", fmt.Errorf("Didn't find the SOURCE_URL for [%s]", binaryName)
+}
@@ -0,0 +1,78 @@
+// checksum.go // This file implements the comparison of files against published or recorded hashes, preferring BLAKE3 over SHA256 //>
+package bigdl
+
+import "fmt"
+
+// Checksum holds the hashes of a binary, hex-encoded. Either may be empty when unknown.
+type Checksum struct {
+	SHA256 string `json:"sha256,omitempty"`
+	B3SUM  string `json:"b3sum,omitempty"`
+}
+
+// Empty reports whether neither hash is known
+func (sum Checksum) Empty() bool {
+	return sum.SHA256 == "" && sum.B3SUM == ""
+}
+
+// String returns the hash that would be compared against, prefixed by its algorithm. e.g: "b3sum:af13..."
+func (sum Checksum) String() string {
+	switch {
+	case sum.B3SUM != "":
+		return "b3sum:" + sum.B3SUM
+	case sum.SHA256 != "":
+		return "sha256:" + sum.SHA256
+	}
+	return "none"
+}
+
+// Equal reports whether both checksums describe the same file, comparing their b3sums if both have one, and their SHA256 otherwise. Unknown hashes are never equal.
+func (sum Checksum) Equal(other Checksum) bool {
+	switch {
+	case sum.B3SUM != "" && other.B3SUM != "":
+		return sum.B3SUM == other.B3SUM
+	case sum.SHA256 != "" && other.SHA256 != "":
+		return sum.SHA256 == other.SHA256
+	}
+	return false
+}
+
+// Checksum returns the hashes the repos publish for the binary
+func (b BinaryInfo) Checksum() Checksum {
+	return Checksum{SHA256: b.SHA256, B3SUM: b.B3SUM}
+}
+
+// fileChecksum hashes a file on demand, with each algorithm at most once
+type fileChecksum struct {
+	path string
+	sum  Checksum // The hashes computed so far
+}
+
+// matches reports whether the file has the expected checksum. BLAKE3 hashes are compared when the expected one has a b3sum, SHA256 hashes otherwise. A file is never said to match an empty Checksum.
+func (f *fileChecksum) matches(expected Checksum) (bool, error) {
+	var err error
+	switch {
+	case expected.B3SUM != "":
+		if f.sum.B3SUM == "" {
+			if f.sum.B3SUM, err = B3SUMFile(f.path); err != nil {
+				return false, err
+			}
+		}
+		return f.sum.B3SUM == expected.B3SUM, nil
+	case expected.SHA256 != "":
+		if f.sum.SHA256 == "" {
+			if f.sum.SHA256, err = SHA256File(f.path); err != nil {
+				return false, err
+			}
+		}
+		return f.sum.SHA256 == expected.SHA256, nil
+	}
+	return false, nil
+}
+
+// mismatch returns the error reported when the file doesn't have the expected checksum
+func (f *fileChecksum) mismatch(binaryName string, expected Checksum) error {
+	if expected.B3SUM != "" {
+		return fmt.Errorf("error: B3SUM mismatch for '%s'. Expected %s, got %s", binaryName, expected.B3SUM, f.sum.B3SUM)
+	}
+	return fmt.Errorf("error: SHA256 mismatch for '%s'. Expected %s, got %s", binaryName, expected.SHA256, f.sum.SHA256)
+}
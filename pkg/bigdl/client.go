@@ -0,0 +1,185 @@
+// client.go // This file implements the Client, through which every operation of bigdl is performed //>
+
+// Package bigdl implements the operations of the bigdl binary manager (install, remove, update, search, info...) so that they can be embedded in other tools. The bigdl command is a thin wrapper around it.
+package bigdl
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Options configures a Client. Use DefaultOptions to get sensible values and change what you need
+type Options struct {
+	// InstallDir is the directory binaries are installed to, removed from and updated in
+	InstallDir string
+	// CacheDir holds the temporary files of downloads, and the binaries cached by `run`
+	CacheDir string
+	// StateDir holds the records of installed binaries and the lock file
+	StateDir string
+	// Arch selects the architecture of the binaries, either as GOARCH_GOOS ("arm64_linux") or as named by the repos ("aarch64_arm64_Linux"). Defaults to the host's
+	Arch string
+	// Repositories are the repos to use, in order of preference. Defaults to DefaultRepositories
+	Repositories []RepositoryTemplate
+	// RepoPriority reorders the Repositories by name. Repos that aren't mentioned keep their order, after the mentioned ones
+	RepoPriority []string
+	// UseCache determines if binaries found in CacheDir are used by Install instead of being downloaded
+	UseCache bool
+	// InstallExtras determines if the companion binaries (extra_bins) of a binary are installed along with it
+	InstallExtras bool
+	// RecordInstalls determines if Install keeps a record of the binaries it installs in StateDir, and if Install, Update and Remove add their changes to the history
+	RecordInstalls bool
+	// UndoableTransactions is the number of transactions that can be undone, the backups of older ones are deleted. Defaults to 10
+	UndoableTransactions int
+	// StaleTempFileAge is the age after which Diagnose considers the .tmp files of a download to be left over by an interrupted bigdl. Defaults to an hour
+	StaleTempFileAge time.Duration
+	// ArchiveEntries holds the entries (by name or path inside of the archive) to be installed out of archives. If empty, every executable in the archive is installed
+	ArchiveEntries []string
+	// ProgressBar determines if a progressbar is shown (on stdout) during downloads
+	ProgressBar bool
+	// HTTPClient is used for every request. Defaults to http.DefaultClient
+	HTTPClient *http.Client
+	// Hooks are run around installs, updates and removals. See LoadHooks
+	Hooks []Hook
+	// HookOutput receives the output of the Hooks. If nil, it is discarded
+	HookOutput io.Writer
+	// Logf receives status messages ("Checking if X is in the repos"), it may be nil
+	Logf func(format string, args ...interface{})
+}
+
+// DefaultOptions returns the Options bigdl uses when no environment variable nor flag changes them: ~/.local/bin, the user's cache directory, ~/.local/state/bigdl and the host's architecture.
+func DefaultOptions() (Options, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return Options{}, fmt.Errorf("failed to get user's Home directory: %v", err)
+	}
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return Options{}, fmt.Errorf("failed to get user's Cache directory: %v", err)
+	}
+	stateDir := os.Getenv("XDG_STATE_HOME")
+	if stateDir == "" {
+		stateDir = filepath.Join(homeDir, ".local", "state")
+	}
+
+	return Options{
+		InstallDir:     filepath.Join(homeDir, ".local", "bin"),
+		CacheDir:       filepath.Join(cacheDir, "bigdl_cache"),
+		StateDir:       filepath.Join(stateDir, "bigdl"),
+		UseCache:       true,
+		InstallExtras:  true,
+		RecordInstalls: true,
+		ProgressBar:    true,
+	}, nil
+}
+
+// Repository is a repo, as resolved for the selected architecture
+type Repository struct {
+	Name        string
+	URL         string
+	MetadataURL string
+}
+
+// Client performs bigdl's operations with the given Options. It is safe for concurrent use
+type Client struct {
+	opts Options
+
+	hostArch      string
+	arch          [3]string
+	repos         []Repository
+	rnMetadataURL string
+
+	// fetchedJSON holds the bodies already downloaded by FetchJSON, so that the metadata is only fetched once per Client
+	fetchedJSON      map[string][]byte
+	fetchedJSONMutex sync.Mutex
+	// catalogues holds the catalogues already loaded by loadCatalogue, by metadata URL
+	catalogues      map[string]*Catalogue
+	cataloguesMutex sync.Mutex
+	// stateMutex serializes the read-modify-write cycles on the state files, updates install in parallel
+	stateMutex sync.Mutex
+}
+
+// New creates a Client, resolving the repos for the selected architecture.
+func New(opts Options) (*Client, error) {
+	if opts.InstallDir == "" || opts.CacheDir == "" || opts.StateDir == "" {
+		return nil, fmt.Errorf("InstallDir, CacheDir and StateDir must be set")
+	}
+	if opts.Repositories == nil {
+		opts.Repositories = append([]RepositoryTemplate(nil), DefaultRepositories...)
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = http.DefaultClient
+	}
+	if opts.UndoableTransactions <= 0 {
+		opts.UndoableTransactions = 10
+	}
+	if opts.StaleTempFileAge <= 0 {
+		opts.StaleTempFileAge = time.Hour
+	}
+
+	c := &Client{
+		opts:        opts,
+		hostArch:    runtime.GOARCH + "_" + runtime.GOOS,
+		fetchedJSON: make(map[string][]byte),
+		catalogues:  make(map[string]*Catalogue),
+	}
+	if c.opts.Arch == "" {
+		c.opts.Arch = c.hostArch
+	}
+	if err := c.setArchitecture(c.opts.Arch); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Options returns the options of the Client
+func (c *Client) Options() Options {
+	return c.opts
+}
+
+// InstallDir returns the directory the Client installs binaries to
+func (c *Client) InstallDir() string {
+	return c.opts.InstallDir
+}
+
+// CacheDir returns the directory the Client downloads to
+func (c *Client) CacheDir() string {
+	return c.opts.CacheDir
+}
+
+// StateDir returns the directory the Client keeps its records in
+func (c *Client) StateDir() string {
+	return c.opts.StateDir
+}
+
+// Arch returns the names the repos use for the selected architecture. e.g: {"x86_64_Linux", "x86_64", "x86_64-Linux"}
+func (c *Client) Arch() [3]string {
+	return c.arch
+}
+
+// IsForeignArch reports whether the selected architecture differs from the one the Client is running on
+func (c *Client) IsForeignArch() bool {
+	return c.arch != SupportedArchs[c.hostArch]
+}
+
+// Repositories returns the repos in use, in order of priority
+func (c *Client) Repositories() []Repository {
+	return append([]Repository(nil), c.repos...)
+}
+
+// IndexURL returns the URL of the metadata that describes the binaries of every repo
+func (c *Client) IndexURL() string {
+	return c.rnMetadataURL
+}
+
+// logf forwards status messages to Options.Logf
+func (c *Client) logf(format string, args ...interface{}) {
+	if c.opts.Logf != nil {
+		c.opts.Logf(format, args...)
+	}
+}
@@ -0,0 +1,159 @@
+// doctor.go // This file implements the health checks of the "doctor" command //>
+package bigdl
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Problem is an issue found by Diagnose, along with the way to fix it
+type Problem struct {
+	Check       string // The check that found it. e.g: "PATH", "repositories"
+	Description string
+	Fix         string
+}
+
+// Diagnose checks the setup of the Client: InstallDir is in $PATH, the binaries installed by bigdl aren't shadowed by others earlier in $PATH, the files of InstallDir are executables built for the selected architecture, there are no stale temporary files and the repos are reachable.
+func (c *Client) Diagnose(ctx context.Context) []Problem {
+	var problems []Problem
+	problems = append(problems, c.checkPath()...)
+	problems = append(problems, c.checkInstallDir()...)
+	problems = append(problems, c.checkTempFiles()...)
+	problems = append(problems, c.checkRepositories(ctx)...)
+	return problems
+}
+
+// checkPath reports an InstallDir that isn't in $PATH, and binaries installed by bigdl that another file of $PATH shadows
+func (c *Client) checkPath() []Problem {
+	installDir := absPath(c.opts.InstallDir)
+	inPath := false
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if absPath(dir) == installDir {
+			inPath = true
+			break
+		}
+	}
+	if !inPath {
+		// Binaries for another architecture aren't meant to be run here
+		if c.IsForeignArch() {
+			return nil
+		}
+		return []Problem{{
+			Check:       "PATH",
+			Description: fmt.Sprintf("%s is not in $PATH, the binaries installed there can't be run by name", installDir),
+			Fix:         fmt.Sprintf("Add 'export PATH=\"%s:$PATH\"' to your shell's profile", installDir),
+		}}
+	}
+
+	c.stateMutex.Lock()
+	installed, err := c.loadInstalled()
+	c.stateMutex.Unlock()
+	if err != nil {
+		return []Problem{{Check: "state", Description: err.Error(), Fix: fmt.Sprintf("Remove %s, the records of installed binaries will be lost", c.installedFile())}}
+	}
+
+	var problems []Problem
+	for installPath := range installed {
+		if filepath.Dir(installPath) != installDir || !FileExists(installPath) {
+			continue
+		}
+		resolved, err := exec.LookPath(filepath.Base(installPath))
+		if err != nil || absPath(resolved) == installPath {
+			continue
+		}
+		problems = append(problems, Problem{
+			Check:       "PATH",
+			Description: fmt.Sprintf("'%s' is shadowed by %s, which comes earlier in $PATH", installPath, resolved),
+			Fix:         fmt.Sprintf("Remove %s, or move %s before %s in $PATH", resolved, installDir, filepath.Dir(resolved)),
+		})
+	}
+	return problems
+}
+
+// checkInstallDir reports the files of InstallDir that aren't executable, or that are built for another architecture
+func (c *Client) checkInstallDir() []Problem {
+	entries, err := os.ReadDir(c.opts.InstallDir)
+	if err != nil {
+		return []Problem{{
+			Check:       "install directory",
+			Description: fmt.Sprintf("%s can't be read: %v", c.opts.InstallDir, err),
+			Fix:         fmt.Sprintf("Create it with 'mkdir -p %s', or set $INSTALL_DIR to another directory", c.opts.InstallDir),
+		}}
+	}
+
+	var problems []Problem
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		filePath := filepath.Join(c.opts.InstallDir, entry.Name())
+		if info, err := entry.Info(); err == nil && info.Mode().Perm()&0o111 == 0 {
+			problems = append(problems, Problem{
+				Check:       "install directory",
+				Description: fmt.Sprintf("'%s' is not executable", filePath),
+				Fix:         fmt.Sprintf("Run 'chmod +x %s', or remove it", filePath),
+			})
+			continue
+		}
+		if report, err := c.Inspect(filePath); err == nil && report.IsELF && !report.MachineMatches {
+			problems = append(problems, Problem{
+				Check:       "install directory",
+				Description: fmt.Sprintf("'%s' is built for %s, not for %s", filePath, strings.TrimPrefix(report.Machine.String(), "EM_"), c.arch[0]),
+				Fix:         fmt.Sprintf("Reinstall it with 'bigdl install %s'", c.CatalogueName(filePath)),
+			})
+		}
+	}
+	return problems
+}
+
+// checkTempFiles reports the temporary files of downloads, in CacheDir and InstallDir, that are older than Options.StaleTempFileAge
+func (c *Client) checkTempFiles() []Problem {
+	var problems []Problem
+	for _, dir := range []string{c.opts.CacheDir, c.opts.InstallDir, c.backupsDir()} {
+		tempFiles, _ := filepath.Glob(filepath.Join(dir, "*.tmp"))
+		for _, tempFile := range tempFiles {
+			if info, err := os.Stat(tempFile); err == nil && time.Since(info.ModTime()) > c.opts.StaleTempFileAge {
+				problems = append(problems, Problem{
+					Check:       "temporary files",
+					Description: fmt.Sprintf("'%s' was left over by an interrupted download", tempFile),
+					Fix:         fmt.Sprintf("Run 'rm %s'", tempFile),
+				})
+			}
+		}
+	}
+	return problems
+}
+
+// checkRepositories reports the repos whose metadata can't be fetched
+func (c *Client) checkRepositories(ctx context.Context) []Problem {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	var problems []Problem
+	for _, repo := range c.repos {
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, repo.MetadataURL, nil)
+		if err != nil {
+			return append(problems, Problem{Check: "repositories", Description: err.Error()})
+		}
+		resp, err := c.opts.HTTPClient.Do(req)
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				continue
+			}
+			err = fmt.Errorf("HTTP status code: %d", resp.StatusCode)
+		}
+		problems = append(problems, Problem{
+			Check:       "repositories",
+			Description: fmt.Sprintf("The %s repo can't be reached (%s): %v", repo.Name, repo.MetadataURL, err),
+			Fix:         "Check your connection, and your proxy settings ($HTTPS_PROXY) if you use one",
+		})
+	}
+	return problems
+}
@@ -0,0 +1,140 @@
+// download.go // This file implements the downloading and verification of binaries //>
+package bigdl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/schollz/progressbar/v3"
+)
+
+// DownloadTo fetches the given URL and writes its body to out, showing the progressbar meanwhile if enabled.
+func (c *Client) DownloadTo(ctx context.Context, url string, out io.Writer) error {
+	return c.downloadTo(ctx, url, out, c.opts.ProgressBar)
+}
+
+func (c *Client) downloadTo(ctx context.Context, url string, out io.Writer, progress bool) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("error creating request: %v", err)
+	}
+
+	resp, err := c.opts.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch binary from %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch binary from %s. HTTP status code: %d", url, resp.StatusCode)
+	}
+
+	bar := spawnProgressBar(resp.ContentLength, progress)
+	defer bar.Close()
+
+	if _, err := io.Copy(io.MultiWriter(out, bar), resp.Body); err != nil {
+		return fmt.Errorf("failed to write the downloaded binary: %v", err)
+	}
+	return nil
+}
+
+// Download fetches a binary from the given URL and saves it to destination, with the executable bit set. The binary is downloaded to a temporary file in CacheDir and inspected with CheckBinary before being moved to destination. Warnings of CheckBinary are returned.
+func (c *Client) Download(ctx context.Context, url, destination string) ([]string, error) {
+	return c.download(ctx, url, destination, c.opts.ProgressBar)
+}
+
+func (c *Client) download(ctx context.Context, url, destination string, progress bool) ([]string, error) {
+	// Create a temporary directory if it doesn't exist
+	if err := os.MkdirAll(c.opts.CacheDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %v", err)
+	}
+
+	// Create a temporary file to download the binary. Its name is unique, so that concurrent downloads of the same binary don't clash
+	out, err := os.CreateTemp(c.opts.CacheDir, filepath.Base(destination)+".*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %v", err)
+	}
+	defer out.Close()
+	tempFile := out.Name()
+
+	// Schedule the deletion of the temporary file, it is gone already if everything went well
+	defer os.Remove(tempFile)
+
+	if err := c.downloadTo(ctx, url, out, progress); err != nil {
+		return nil, err
+	}
+
+	// Close the file before setting executable bit
+	if err := out.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close temporary file: %v", err)
+	}
+
+	// Refuse binaries built for another architecture, and warn about dynamically linked ones
+	warnings, err := c.CheckBinary(filepath.Base(destination), tempFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return warnings, c.moveExecutable(tempFile, destination)
+}
+
+// moveExecutable moves the file at src to dst and sets its executable bit
+func (c *Client) moveExecutable(src, dst string) error {
+	if err := copyFile(src, dst); err != nil {
+		return fmt.Errorf("failed to move binary to destination: %v", err)
+	}
+
+	// Set executable bit immediately after copying
+	if err := os.Chmod(dst, 0o755); err != nil {
+		return fmt.Errorf("failed to set executable bit: %v", err)
+	}
+	return nil
+}
+
+// VerifyFile compares the hash of the file at filePath against the one in the metadata of binaryName, its b3sum if there's one and its SHA256 otherwise. It returns false, without an error, if no metadata describes the binary or if it has no hash to compare against. Failing to fetch the metadata is an error.
+func (c *Client) VerifyFile(ctx context.Context, binaryName, filePath string) (bool, error) {
+	binaryInfo, found, err := c.lookup(ctx, binaryName)
+	if err != nil {
+		return false, err
+	}
+	if !found || binaryInfo.Checksum().Empty() {
+		return false, nil
+	}
+
+	local := &fileChecksum{path: filePath}
+	matches, err := local.matches(binaryInfo.Checksum())
+	if err != nil {
+		return false, err
+	}
+	if !matches {
+		return false, local.mismatch(binaryName, binaryInfo.Checksum())
+	}
+	return true, nil
+}
+
+// spawnProgressBar returns a progressbar for a download of contentLength bytes, which is invisible if show is false
+func spawnProgressBar(contentLength int64, show bool) *progressbar.ProgressBar {
+	if show {
+		return progressbar.NewOptions(int(contentLength),
+			progressbar.OptionClearOnFinish(),
+			progressbar.OptionFullWidth(),
+			progressbar.OptionShowBytes(true),
+			progressbar.OptionSetTheme(progressbar.Theme{
+				Saucer:        "=",
+				SaucerHead:    ">",
+				SaucerPadding: " ",
+				BarStart:      "[",
+				BarEnd:        "]",
+			}),
+		)
+	}
+	return progressbar.NewOptions(-1,
+		progressbar.OptionSetWriter(io.Discard),
+		progressbar.OptionSetVisibility(false),
+		progressbar.OptionShowBytes(false),
+	)
+}
@@ -0,0 +1,43 @@
+package bigdl
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestVerifyFile(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t)
+	env.repo.publish("hello", script("hello"))
+
+	local := filepath.Join(env.dir, "hello")
+	if err := os.WriteFile(local, script("hello"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if verified, err := env.client().VerifyFile(ctx, "hello", local); err != nil || !verified {
+		t.Errorf("the published file: %v, %v", verified, err)
+	}
+	if verified, err := env.client().VerifyFile(ctx, "missing", local); err != nil || verified {
+		t.Errorf("a binary the metadata doesn't describe: %v, %v", verified, err)
+	}
+
+	if err := os.WriteFile(local, script("tampered"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := env.client().VerifyFile(ctx, "hello", local); err == nil {
+		t.Error("a file that doesn't match its hash should be an error")
+	}
+
+	// Failing to fetch the metadata doesn't make the file unverified, it's an error
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := env.client().VerifyFile(cancelled, "hello", local); err == nil {
+		t.Error("a cancelled context should be an error")
+	}
+	env.repo.server.Close()
+	if _, err := env.client().VerifyFile(ctx, "hello", local); err == nil {
+		t.Error("an unreachable repo should be an error")
+	}
+}
@@ -0,0 +1,153 @@
+// elf.go // This file implements the inspection of downloaded binaries (architecture, static linking, interpreters) //>
+package bigdl
+
+import (
+	"bufio"
+	"debug/elf"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// archELFMachines maps the first name of SupportedArchs to the ELF machine type its binaries must have
+var archELFMachines = map[string]elf.Machine{
+	"x86_64_Linux":        elf.EM_X86_64,
+	"aarch64_arm64_Linux": elf.EM_AARCH64,
+	"arm64_v8a_Android":   elf.EM_AARCH64,
+	"riscv64_Linux":       elf.EM_RISCV,
+	"armv7_Linux":         elf.EM_ARM,
+}
+
+// libraryDirs are the directories searched for the shared libraries that dynamic binaries need
+var libraryDirs = []string{"/lib", "/lib64", "/usr/lib", "/usr/lib64", "/usr/local/lib", "/system/lib64", "/system/lib"}
+
+// ELFReport holds the result of inspecting a file with inspectBinary
+type ELFReport struct {
+	IsELF            bool
+	IsScript         bool
+	Shebang          string // Interpreter line of scripts, without the "#!"
+	Machine          elf.Machine
+	MachineMatches   bool
+	ExpectedArch     string // The architecture the binary was checked against
+	Interpreter      string // PT_INTERP, only set for dynamically linked binaries
+	Libraries        []string
+	MissingLibraries []string
+}
+
+// Static reports whether the file is a statically linked ELF
+func (r ELFReport) Static() bool {
+	return r.IsELF && r.Interpreter == "" && len(r.Libraries) == 0
+}
+
+// String summarizes the report in a single line, as shown by `info`
+func (r ELFReport) String() string {
+	switch {
+	case r.IsScript:
+		return "script (#!" + r.Shebang + ")"
+	case !r.IsELF:
+		return "unknown format (not an ELF nor a script)"
+	}
+
+	summary := strings.TrimPrefix(r.Machine.String(), "EM_") + " ELF, "
+	if r.Static() {
+		summary += "statically linked"
+	} else {
+		summary += "dynamically linked (interpreter: " + r.Interpreter + ")"
+		if len(r.MissingLibraries) > 0 {
+			summary += ", missing: " + strings.Join(r.MissingLibraries, ", ")
+		}
+	}
+	if !r.MachineMatches {
+		summary += fmt.Sprintf(", does NOT match %s", r.ExpectedArch)
+	}
+	return summary
+}
+
+// Inspect reads the file at filePath, checking its machine type against the selected architecture, whether it is statically linked, and if it is a script.
+func (c *Client) Inspect(filePath string) (ELFReport, error) {
+	report := ELFReport{ExpectedArch: c.arch[0]}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return report, fmt.Errorf("failed to open file: %v", err)
+	}
+	defer file.Close()
+
+	// Scripts are detected by their shebang
+	firstLine, _ := bufio.NewReader(file).ReadString('\n')
+	if strings.HasPrefix(firstLine, "#!") {
+		report.IsScript = true
+		report.Shebang = strings.TrimSpace(strings.TrimPrefix(firstLine, "#!"))
+		report.MachineMatches = true
+		return report, nil
+	}
+
+	elfFile, err := elf.NewFile(file)
+	if err != nil {
+		return report, nil // Neither a script nor an ELF, the caller decides what to make of it
+	}
+	defer elfFile.Close()
+
+	report.IsELF = true
+	report.Machine = elfFile.Machine
+	report.MachineMatches = archELFMachines[c.arch[0]] == elfFile.Machine
+
+	for _, prog := range elfFile.Progs {
+		if prog.Type == elf.PT_INTERP {
+			interp := make([]byte, prog.Filesz)
+			if _, err := prog.ReadAt(interp, 0); err == nil {
+				report.Interpreter = strings.TrimRight(string(interp), "\x00")
+			}
+		}
+	}
+
+	report.Libraries, _ = elfFile.ImportedLibraries()
+	// Missing libraries can only be determined for binaries meant for this machine
+	if !c.IsForeignArch() {
+		for _, lib := range report.Libraries {
+			if !libraryExists(lib) {
+				report.MissingLibraries = append(report.MissingLibraries, lib)
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// libraryExists checks if the shared library is present in any of the libraryDirs or their multiarch subdirectories
+func libraryExists(lib string) bool {
+	for _, dir := range libraryDirs {
+		if FileExists(filepath.Join(dir, lib)) {
+			return true
+		}
+		if matches, _ := filepath.Glob(filepath.Join(dir, "*-linux-*", lib)); len(matches) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckBinary inspects a downloaded file before it gets installed. A machine type mismatch is an error, while dynamic linking and missing libraries only produce warnings, which are returned.
+func (c *Client) CheckBinary(binaryName, filePath string) ([]string, error) {
+	report, err := c.Inspect(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if report.IsELF && !report.MachineMatches {
+		return nil, fmt.Errorf("error: '%s' is built for %s, not for %s", binaryName, strings.TrimPrefix(report.Machine.String(), "EM_"), c.arch[0])
+	}
+
+	var warnings []string
+	switch {
+	case report.IsELF && !report.Static():
+		warnings = append(warnings, fmt.Sprintf("'%s' is dynamically linked (interpreter: %s)", binaryName, report.Interpreter))
+		if len(report.MissingLibraries) > 0 {
+			warnings = append(warnings, fmt.Sprintf("'%s' needs libraries that weren't found: %s", binaryName, strings.Join(report.MissingLibraries, ", ")))
+		}
+	case !report.IsELF && !report.IsScript:
+		warnings = append(warnings, fmt.Sprintf("'%s' is neither an ELF binary nor a script", binaryName))
+	}
+	return warnings, nil
+}
@@ -0,0 +1,124 @@
+package bigdl
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// elfBinary returns a minimal 64-bit ELF executable for the machine, which is dynamically linked if interpreter is set
+func elfBinary(t *testing.T, machine elf.Machine, interpreter string) []byte {
+	t.Helper()
+	header := elf.Header64{
+		Type:      uint16(elf.ET_EXEC),
+		Machine:   uint16(machine),
+		Version:   uint32(elf.EV_CURRENT),
+		Ehsize:    64,
+		Phentsize: 56,
+		Shentsize: 64,
+	}
+	copy(header.Ident[:], elf.ELFMAG)
+	header.Ident[elf.EI_CLASS] = byte(elf.ELFCLASS64)
+	header.Ident[elf.EI_DATA] = byte(elf.ELFDATA2LSB)
+	header.Ident[elf.EI_VERSION] = byte(elf.EV_CURRENT)
+
+	var progs []elf.Prog64
+	if interpreter != "" {
+		header.Phoff, header.Phnum = 64, 1
+		progs = append(progs, elf.Prog64{Type: uint32(elf.PT_INTERP), Off: 64 + 56, Filesz: uint64(len(interpreter) + 1), Memsz: uint64(len(interpreter) + 1)})
+	}
+
+	var buf bytes.Buffer
+	for _, data := range []interface{}{header, progs} {
+		if err := binary.Write(&buf, binary.LittleEndian, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if interpreter != "" {
+		buf.WriteString(interpreter + "\x00")
+	}
+	return buf.Bytes()
+}
+
+// inspectedFile writes the content to a temporary file, for Inspect and CheckBinary
+func inspectedFile(t *testing.T, content []byte) string {
+	t.Helper()
+	filePath := filepath.Join(t.TempDir(), "binary")
+	if err := os.WriteFile(filePath, content, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return filePath
+}
+
+// newArchClient returns a Client for the architecture, which is enough to inspect binaries
+func newArchClient(t *testing.T, arch string) *Client {
+	t.Helper()
+	dir := t.TempDir()
+	client, err := New(Options{InstallDir: dir, CacheDir: dir, StateDir: dir, Arch: arch})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestInspect(t *testing.T) {
+	client := newArchClient(t, "amd64_linux")
+
+	report, err := client.Inspect(inspectedFile(t, []byte("#!/bin/sh -e\necho hi\n")))
+	if err != nil || !report.IsScript || report.IsELF || report.Shebang != "/bin/sh -e" || !report.MachineMatches {
+		t.Errorf("script: %+v, %v", report, err)
+	}
+
+	report, err = client.Inspect(inspectedFile(t, elfBinary(t, elf.EM_X86_64, "")))
+	if err != nil || !report.IsELF || !report.Static() || !report.MachineMatches {
+		t.Errorf("static x86_64 ELF: %+v, %v", report, err)
+	}
+
+	report, err = client.Inspect(inspectedFile(t, elfBinary(t, elf.EM_X86_64, "/lib64/ld-linux-x86-64.so.2")))
+	if err != nil || report.Static() || report.Interpreter != "/lib64/ld-linux-x86-64.so.2" {
+		t.Errorf("dynamic x86_64 ELF: %+v, %v", report, err)
+	}
+
+	report, err = client.Inspect(inspectedFile(t, elfBinary(t, elf.EM_AARCH64, "")))
+	if err != nil || !report.IsELF || report.MachineMatches || report.Machine != elf.EM_AARCH64 {
+		t.Errorf("aarch64 ELF: %+v, %v", report, err)
+	}
+
+	report, err = client.Inspect(inspectedFile(t, []byte("plain text")))
+	if err != nil || report.IsELF || report.IsScript {
+		t.Errorf("text file: %+v, %v", report, err)
+	}
+}
+
+func TestCheckBinary(t *testing.T) {
+	client := newArchClient(t, "amd64_linux")
+
+	if warnings, err := client.CheckBinary("static", inspectedFile(t, elfBinary(t, elf.EM_X86_64, ""))); err != nil || len(warnings) != 0 {
+		t.Errorf("static binary: %q, %v", warnings, err)
+	}
+	if warnings, err := client.CheckBinary("script", inspectedFile(t, []byte("#!/bin/sh\n"))); err != nil || len(warnings) != 0 {
+		t.Errorf("script: %q, %v", warnings, err)
+	}
+
+	// A binary for another machine is rejected, while dynamic linking and unknown formats are only warned about
+	if _, err := client.CheckBinary("arm", inspectedFile(t, elfBinary(t, elf.EM_AARCH64, ""))); err == nil {
+		t.Error("an aarch64 binary should be rejected on x86_64")
+	}
+	warnings, err := client.CheckBinary("dynamic", inspectedFile(t, elfBinary(t, elf.EM_X86_64, "/lib64/ld-linux-x86-64.so.2")))
+	if err != nil || len(warnings) == 0 || !strings.Contains(warnings[0], "dynamically linked (interpreter: /lib64/ld-linux-x86-64.so.2)") {
+		t.Errorf("dynamic binary: %q, %v", warnings, err)
+	}
+	warnings, err = client.CheckBinary("text", inspectedFile(t, []byte("plain text")))
+	if err != nil || len(warnings) != 1 || !strings.Contains(warnings[0], "neither an ELF binary nor a script") {
+		t.Errorf("text file: %q, %v", warnings, err)
+	}
+
+	// The architecture is the one of the Client, not the machine's
+	if _, err := newArchClient(t, "arm64_linux").CheckBinary("arm", inspectedFile(t, elfBinary(t, elf.EM_AARCH64, ""))); err != nil {
+		t.Errorf("an aarch64 binary should be accepted for arm64_linux: %v", err)
+	}
+}
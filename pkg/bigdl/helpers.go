@@ -0,0 +1,146 @@
+// helpers.go // This file contains commonly used functions //>
+package bigdl
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"lukechampine.com/blake3"
+)
+
+// removeDuplicates removes duplicate elements from the input slice, keeping the first occurrence.
+func removeDuplicates(input []string) []string {
+	seen := make(map[string]bool)
+	var unique []string
+	for _, entry := range input {
+		if !seen[entry] {
+			seen[entry] = true
+			unique = append(unique, entry)
+		}
+	}
+	return unique
+}
+
+// sortResults sorts search results by name
+func sortResults(results []SearchResult) {
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+}
+
+// contains will return true if the provided slice of []strings contains the word str
+func contains(slice []string, str string) bool {
+	for _, v := range slice {
+		if v == str {
+			return true
+		}
+	}
+	return false
+}
+
+// FileExists checks if a file exists.
+func FileExists(filePath string) bool {
+	_, err := os.Stat(filePath)
+	return !os.IsNotExist(err)
+}
+
+// IsExecutable checks if the file at the specified path is a regular file with any executable bit set.
+func IsExecutable(filePath string) bool {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return false
+	}
+	return info.Mode().IsRegular() && (info.Mode().Perm()&0o111) != 0
+}
+
+// listFilesInDir lists all files in a directory
+func listFilesInDir(dir string) ([]string, error) {
+	var files []string
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	return files, nil
+}
+
+// absPath returns the absolute version of path, or path itself if it can't be determined
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// SHA256File calculates the SHA256 checksum of the file.
+func SHA256File(filePath string) (string, error) {
+	return hashFile(filePath, sha256.New(), "SHA256")
+}
+
+// B3SUMFile calculates the BLAKE3 checksum of the file, which is much faster than SHA256 on machines without SHA extensions.
+func B3SUMFile(filePath string) (string, error) {
+	return hashFile(filePath, blake3.New(32, nil), "BLAKE3")
+}
+
+// hashFile feeds the file to hasher and returns its hex-encoded sum. algorithm names the hash in errors.
+func hashFile(filePath string, hasher hash.Hash, algorithm string) (string, error) {
+	// Open the file for reading
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %v", err)
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(hasher, file); err != nil {
+		return "", fmt.Errorf("failed to calculate %s: %v", algorithm, err)
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// copyFile copies(removes original after copy) a file from src to dst
+func copyFile(src, dst string) error {
+	// Check if the destination file already exists
+	if FileExists(dst) {
+		if err := os.Remove(dst); err != nil {
+			return fmt.Errorf("%v", err)
+		}
+	}
+
+	sourceFile, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open source file: %v", err)
+	}
+	defer sourceFile.Close()
+
+	destFile, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("failed to create destination file: %v", err)
+	}
+
+	_, err = io.Copy(destFile, sourceFile)
+	if err != nil {
+		destFile.Close() // Ensure the destination file is closed
+		return fmt.Errorf("failed to copy file: %v", err)
+	}
+
+	if err := destFile.Close(); err != nil {
+		return fmt.Errorf("failed to close destination file: %v", err)
+	}
+
+	// Remove the temporary file after copying
+	if err := os.Remove(src); err != nil {
+		return fmt.Errorf("failed to remove source file: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+// history.go // This file keeps the history of what install, update and remove changed, in StateDir //>
+package bigdl
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/goccy/go-json"
+)
+
+// HistoryEntry records a change made to a binary of InstallDir
+type HistoryEntry struct {
+	Time time.Time `json:"time"`
+	// Transaction is shared by the entries of a single Install, Update or Remove
+	Transaction string `json:"transaction"`
+	Command     string `json:"command"` // "install", "update" or "remove"
+	Binary      string `json:"binary"`  // Name of the binary in the repos
+	Path        string `json:"path"`
+	OldSHA256   string `json:"old_sha256,omitempty"` // SHA256 of the file at Path before the change, if there was one
+	NewSHA256   string `json:"new_sha256,omitempty"` // SHA256 of the file at Path after the change, if there is one
+	Version     string `json:"version,omitempty"`
+	Result      string `json:"result"` // "ok", or the error that stopped the change
+	// Reverts is the Transaction whose change this entry undid, for the entries of Undo
+	Reverts string `json:"reverts,omitempty"`
+	// OldRecord is the install record of the file at Path before the change, nil if bigdl didn't install it. Undo restores it along with the file
+	OldRecord *InstalledBinary `json:"old_record,omitempty"`
+}
+
+// historyFile returns the path of the file that holds the HistoryEntry records, one JSON object per line
+func (c *Client) historyFile() string {
+	return filepath.Join(c.opts.StateDir, "history.jsonl")
+}
+
+// newTransaction returns a unique identifier for the entries of an operation
+func newTransaction() string {
+	return strconv.FormatInt(time.Now().UnixNano(), 36)
+}
+
+// appendHistory adds the entry to the history. Nothing is stored if Options.RecordInstalls is false.
+func (c *Client) appendHistory(entry HistoryEntry) error {
+	if !c.opts.RecordInstalls {
+		return nil
+	}
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
+
+	if err := os.MkdirAll(c.opts.StateDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create state directory: %v", err)
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	historyFile, err := os.OpenFile(c.historyFile(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %v", c.historyFile(), err)
+	}
+	defer historyFile.Close()
+	if _, err := historyFile.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write %s: %v", c.historyFile(), err)
+	}
+	return nil
+}
+
+// recordChange appends an entry describing the change of the file at installPath, whose SHA256 and install record were oldSHA256 and oldRecord before it, to the history. A failure to do so is logged.
+func (c *Client) recordChange(ctx context.Context, transaction, command, binaryName, installPath, oldSHA256 string, oldRecord *InstalledBinary, changeErr error) {
+	c.recordEntry(c.newChange(ctx, transaction, command, binaryName, installPath, oldSHA256, oldRecord, changeErr))
+}
+
+// newChange describes the change of the file at installPath, whose SHA256 and install record were oldSHA256 and oldRecord before it
+func (c *Client) newChange(ctx context.Context, transaction, command, binaryName, installPath, oldSHA256 string, oldRecord *InstalledBinary, changeErr error) HistoryEntry {
+	entry := HistoryEntry{
+		Time:        time.Now(),
+		Transaction: transaction,
+		Command:     command,
+		Binary:      binaryName,
+		Path:        absPath(installPath),
+		OldSHA256:   oldSHA256,
+		OldRecord:   oldRecord,
+		Result:      "ok",
+	}
+	if changeErr != nil {
+		entry.Result = changeErr.Error()
+	}
+	if FileExists(installPath) {
+		entry.NewSHA256, _ = SHA256File(installPath)
+	}
+	// The metadata is loaded already, install and update needed it
+	if binaryInfo, err := c.Info(ctx, binaryName); err == nil {
+		entry.Version = binaryInfo.Version
+	}
+	return entry
+}
+
+// recordEntry appends the entry to the history, logging a failure to do so
+func (c *Client) recordEntry(entry HistoryEntry) {
+	if err := c.appendHistory(entry); err != nil {
+		c.logf("Warning: %v\n", err)
+	}
+}
+
+// fileSHA256 returns the SHA256 of the file at filePath, or an empty string if there's no such file
+func fileSHA256(filePath string) string {
+	sha256, _ := SHA256File(filePath)
+	return sha256
+}
+
+// History returns the entries of the history, oldest first. If binaryName is not empty, only the entries of that binary (by name in the repos, or by file name) are returned.
+func (c *Client) History(binaryName string) ([]HistoryEntry, error) {
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
+
+	historyFile, err := os.Open(c.historyFile())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %v", c.historyFile(), err)
+	}
+	defer historyFile.Close()
+
+	var entries []HistoryEntry
+	scanner := bufio.NewScanner(historyFile)
+	for scanner.Scan() {
+		var entry HistoryEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue // A line cut short by a crash shouldn't hide the rest of the history
+		}
+		if binaryName != "" && entry.Binary != binaryName && filepath.Base(entry.Path) != binaryName {
+			if _, name := c.SplitRepoName(entry.Binary); name != binaryName {
+				continue
+			}
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", c.historyFile(), err)
+	}
+	return entries, nil
+}
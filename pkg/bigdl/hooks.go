@@ -0,0 +1,142 @@
+// hooks.go // This file implements the user-configured hooks that run around installs, updates and removals //>
+package bigdl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sync"
+
+	"github.com/goccy/go-json"
+)
+
+// HookEvent names the moment at which a Hook runs
+type HookEvent string
+
+const (
+	PreInstall  HookEvent = "pre-install"
+	PostInstall HookEvent = "post-install"
+	PreUpdate   HookEvent = "pre-update"
+	PostUpdate  HookEvent = "post-update"
+	PreRemove   HookEvent = "pre-remove"
+	PostRemove  HookEvent = "post-remove"
+)
+
+// Hook is a shell command run (with sh -c) when Event happens to a binary matching Match
+// The command receives the binary in its environment: BIGDL_HOOK (the event), BIGDL_BINARY (its name in the repos), BIGDL_PATH (its install path), BIGDL_VERSION and BIGDL_SHA256
+type Hook struct {
+	Event HookEvent `json:"event"`
+	// Match is a glob (see path.Match) checked against the name of the binary in the repos ("bash/bash"), its base name and the name it is installed as. Empty matches every binary
+	Match string `json:"match,omitempty"`
+	Run   string `json:"run"`
+}
+
+// LoadHooks reads the hooks declared in the JSON file at hooksFile, a list of Hook. A missing file is not an error.
+func LoadHooks(hooksFile string) ([]Hook, error) {
+	data, err := os.ReadFile(hooksFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %v", hooksFile, err)
+	}
+	var hooks []Hook
+	if err := json.Unmarshal(data, &hooks); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %v", hooksFile, err)
+	}
+	for _, hook := range hooks {
+		switch hook.Event {
+		case PreInstall, PostInstall, PreUpdate, PostUpdate, PreRemove, PostRemove:
+		default:
+			return nil, fmt.Errorf("error: %s declares a hook for an unknown event '%s'", hooksFile, hook.Event)
+		}
+		if _, err := path.Match(hook.Match, ""); err != nil {
+			return nil, fmt.Errorf("error: %s declares a hook with a malformed match '%s'", hooksFile, hook.Match)
+		}
+	}
+	return hooks, nil
+}
+
+// hookTarget describes the binary a hook runs for. version and sha256 are only looked up (once) if a hook matches
+type hookTarget struct {
+	name, path string
+	version    func() string
+	sha256     func() string
+}
+
+// remoteTarget describes the binary of the repos, whose name is binaryName, that is about to be installed at installPath
+func (c *Client) remoteTarget(ctx context.Context, binaryName, installPath string) hookTarget {
+	info := sync.OnceValue(func() BinaryInfo {
+		if binaryInfo, err := c.Info(ctx, binaryName); err == nil {
+			return *binaryInfo
+		}
+		return BinaryInfo{}
+	})
+	return hookTarget{
+		name:    binaryName,
+		path:    installPath,
+		version: func() string { return info().Version },
+		sha256:  func() string { return info().SHA256 },
+	}
+}
+
+// hooksFor returns the hooks of Options.Hooks that run on event for the target
+func (c *Client) hooksFor(event HookEvent, target hookTarget) []Hook {
+	var hooks []Hook
+	for _, hook := range c.opts.Hooks {
+		if hook.Event != event {
+			continue
+		}
+		for _, name := range []string{target.name, path.Base(target.name), filepath.Base(target.path)} {
+			if matched, _ := path.Match(hook.Match, name); matched || hook.Match == "" {
+				hooks = append(hooks, hook)
+				break
+			}
+		}
+	}
+	return hooks
+}
+
+// runHooks runs the hooks of event that match the target, in the order they were declared. A failing hook doesn't stop the others, its error is returned along with theirs.
+func (c *Client) runHooks(ctx context.Context, event HookEvent, target hookTarget) []error {
+	hooks := c.hooksFor(event, target)
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	env := append(os.Environ(),
+		"BIGDL_HOOK="+string(event),
+		"BIGDL_BINARY="+target.name,
+		"BIGDL_PATH="+target.path,
+		"BIGDL_VERSION="+target.version(),
+		"BIGDL_SHA256="+target.sha256(),
+	)
+
+	var errs []error
+	for _, hook := range hooks {
+		cmd := exec.CommandContext(ctx, "sh", "-c", hook.Run)
+		cmd.Env = env
+		cmd.Stdout, cmd.Stderr = c.opts.HookOutput, c.opts.HookOutput
+		if cmd.Stdout == nil {
+			cmd.Stdout, cmd.Stderr = io.Discard, io.Discard
+		}
+		if err := cmd.Run(); err != nil {
+			errs = append(errs, fmt.Errorf("%s hook '%s' failed for '%s': %v", event, hook.Run, target.name, err))
+		}
+	}
+	return errs
+}
+
+// installedTarget describes the binary at installPath, whose name in the repos is binaryName, for the hooks
+func (c *Client) installedTarget(ctx context.Context, binaryName, installPath string) hookTarget {
+	target := c.remoteTarget(ctx, binaryName, installPath)
+	target.sha256 = sync.OnceValue(func() string {
+		sha256, _ := SHA256File(installPath)
+		return sha256
+	})
+	return target
+}
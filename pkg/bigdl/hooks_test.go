@@ -0,0 +1,73 @@
+package bigdl
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadHooks(t *testing.T) {
+	dir := t.TempDir()
+	if hooks, err := LoadHooks(filepath.Join(dir, "missing.json")); err != nil || hooks != nil {
+		t.Errorf("a missing file: %+v, %v", hooks, err)
+	}
+
+	tests := []struct {
+		content string
+		valid   bool
+	}{
+		{`[{"event": "post-install", "match": "*grep", "run": "echo $BIGDL_PATH"}, {"event": "pre-remove", "run": "true"}]`, true},
+		{`[{"event": "post-download", "run": "true"}]`, false},
+		{`[{"event": "post-install", "match": "[", "run": "true"}]`, false},
+		{`{"event": "post-install"}`, false},
+	}
+	for _, test := range tests {
+		hooksFile := filepath.Join(dir, "hooks.json")
+		if err := os.WriteFile(hooksFile, []byte(test.content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		hooks, err := LoadHooks(hooksFile)
+		if (err == nil) != test.valid {
+			t.Errorf("LoadHooks(%s) = %+v, %v", test.content, hooks, err)
+		}
+	}
+}
+
+func TestRunHooks(t *testing.T) {
+	var output bytes.Buffer
+	client := newArchClient(t, "amd64_linux")
+	client.opts.HookOutput = &output
+	client.opts.Hooks = []Hook{
+		{Event: PostInstall, Match: "wget", Run: `echo "$BIGDL_HOOK $BIGDL_BINARY $BIGDL_PATH $BIGDL_VERSION $BIGDL_SHA256"`},
+		{Event: PostInstall, Run: "exit 3"},
+		{Event: PostInstall, Match: "twget", Run: "echo installed as twget"},
+		{Event: PostInstall, Match: "curl", Run: "echo curl"},
+		{Event: PreInstall, Run: "echo pre-install"},
+	}
+
+	looked := false
+	target := hookTarget{
+		name:    "toybox/wget",
+		path:    "/opt/bin/twget",
+		version: func() string { looked = true; return "1.21" },
+		sha256:  func() string { return "abc" },
+	}
+
+	// Every matching hook runs, in order, even after one of them failed
+	errs := client.runHooks(context.Background(), PostInstall, target)
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "post-install hook 'exit 3' failed for 'toybox/wget'") {
+		t.Errorf("errors = %v", errs)
+	}
+	if want := "post-install toybox/wget /opt/bin/twget 1.21 abc\ninstalled as twget\n"; output.String() != want {
+		t.Errorf("output = %q, want %q", output.String(), want)
+	}
+
+	// The version and hash are only looked up for hooks that run
+	looked = false
+	if errs := client.runHooks(context.Background(), PostRemove, target); errs != nil || looked {
+		t.Errorf("post-remove has no hooks, but ran with %v (looked up the version: %v)", errs, looked)
+	}
+}
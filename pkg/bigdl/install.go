@@ -0,0 +1,164 @@
+// install.go // This file implements the install functionality //>
+package bigdl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// InstallResult describes a binary installed by Install
+type InstallResult struct {
+	Name       string   // Name of the binary in the repos
+	Path       string   // Where it was installed
+	FromCache  bool     // It was taken from CacheDir instead of being downloaded
+	Archive    string   // The archive it was extracted from, if any
+	Extras     []string // Companion binaries (extra_bins) installed along with it, they have their own InstallResult
+	Warnings   []string // Warnings about the binary, e.g: it is dynamically linked
+	HookErrors []error  // Failures of the pre-install and post-install hooks. They don't stop the installation
+}
+
+// ParseInstallName splits "src:dest" into the name of the binary in the repos and the name it is to be installed as. Without a ":", the binary is installed under its base name.
+func ParseInstallName(binaryName string) (string, string) {
+	if src, dest, found := strings.Cut(binaryName, ":"); found && src != "" && dest != "" {
+		return src, filepath.Base(dest)
+	}
+	return binaryName, filepath.Base(binaryName)
+}
+
+// Install installs the binaries to InstallDir. Names can be repository-qualified ("Baseutils/ls"), aliased ("toybox/wget:twget") or point to archives ("foo.tar.gz").
+// It stops at the first failure, returning the binaries installed so far along with the error.
+func (c *Client) Install(ctx context.Context, binaryNames []string) ([]InstallResult, error) {
+	mode := installMode{extras: c.opts.InstallExtras, cache: c.opts.UseCache, progress: c.opts.ProgressBar, hooks: true, entries: c.opts.ArchiveEntries, transaction: newTransaction()}
+	defer c.pruneBackups()
+	return c.install(ctx, binaryNames, mode)
+}
+
+// installMode overrides the Options of the Client for a single call of install. e.g: updates never use the cache, and run their own hooks
+type installMode struct {
+	extras, cache, progress, hooks bool
+	// entries are the files installed out of archives, see Options.ArchiveEntries
+	entries []string
+	// transaction identifies the install in the history. Updates record themselves, they leave it empty
+	transaction string
+}
+
+// recordChange adds the installation of the binary at installPath to the history, if the mode has a transaction
+func (mode installMode) recordChange(ctx context.Context, c *Client, binaryName, installPath, oldSHA256 string, oldRecord *InstalledBinary, err error) {
+	if mode.transaction != "" {
+		c.recordChange(ctx, mode.transaction, "install", binaryName, installPath, oldSHA256, oldRecord, err)
+	}
+}
+
+func (c *Client) install(ctx context.Context, binaryNames []string, mode installMode) ([]InstallResult, error) {
+	if err := os.MkdirAll(c.opts.InstallDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %v", c.opts.InstallDir, err)
+	}
+
+	var results []InstallResult
+	for _, binaryName := range binaryNames {
+		// Once cancelled, nothing else is installed. What was installed so far is kept, and recorded
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+
+		// "src:dest" installs src under the name dest. Otherwise, extract the last part of the binaryName to use as the filename
+		binaryName, fileName := ParseInstallName(binaryName)
+
+		// Construct the installPath using the extracted filename
+		installPath := filepath.Join(c.opts.InstallDir, fileName)
+
+		// Archives are extracted, and their executables installed
+		if archiveExtension(binaryName) != "" {
+			if fileName != filepath.Base(binaryName) {
+				return results, fmt.Errorf("error: archives can't be installed under another name, use --entry to pick their files")
+			}
+			archiveResults, err := c.installFromArchive(ctx, binaryName, mode)
+			results = append(results, archiveResults...)
+			if err != nil {
+				return results, err
+			}
+			continue
+		}
+
+		result := InstallResult{Name: binaryName, Path: installPath}
+		oldSHA256, oldRecord := c.backup(installPath), c.installedRecord(installPath)
+		fail := func(err error) ([]InstallResult, error) {
+			mode.recordChange(ctx, c, binaryName, installPath, oldSHA256, oldRecord, err)
+			return results, err
+		}
+		if mode.hooks {
+			result.HookErrors = c.runHooks(ctx, PreInstall, c.remoteTarget(ctx, binaryName, installPath))
+		}
+
+		// Use the cached file if there's one
+		cachedFile := filepath.Join(c.opts.CacheDir, binaryName)
+		if mode.cache && FileExists(cachedFile) {
+			if err := c.moveExecutable(cachedFile, installPath); err != nil {
+				return fail(fmt.Errorf("error: Could not copy cached file: %v", err))
+			}
+			result.FromCache = true
+		} else {
+			// If the cached file does not exist, download the binary
+			url, err := c.FindURL(ctx, binaryName)
+			if err != nil {
+				return fail(err)
+			}
+			if result.Warnings, err = c.download(ctx, url, installPath, mode.progress); err != nil {
+				return fail(err)
+			}
+		}
+
+		mode.recordChange(ctx, c, binaryName, installPath, oldSHA256, oldRecord, nil)
+		extraResults, err := c.installExtras(ctx, &result, mode)
+		if mode.hooks && err == nil {
+			result.HookErrors = append(result.HookErrors, c.runHooks(ctx, PostInstall, c.installedTarget(ctx, binaryName, installPath))...)
+		}
+		results = append(results, result)
+		results = append(results, extraResults...)
+		if err != nil {
+			return results, err
+		}
+	}
+	return results, nil
+}
+
+// installExtras installs the companion binaries (extra_bins) of the binary, if it declares any and mode.extras is set, and records them as a group with it.
+// Without mode.extras (e.g: updates), the group the binary was installed with is kept.
+func (c *Client) installExtras(ctx context.Context, result *InstallResult, mode installMode) ([]InstallResult, error) {
+	record := InstalledBinary{Name: result.Name}
+	if previous, ok := c.Installed(result.Path); ok && previous.Name == result.Name && !mode.extras {
+		record.Extras = previous.Extras
+	}
+
+	var extraResults []InstallResult
+	if mode.extras {
+		// The extras live next to the binary in the repo. e.g: "bash/bash" has "sh" -> "bash/sh"
+		if binaryInfo, err := c.Info(ctx, result.Name); err == nil && binaryInfo.Extras != "" {
+			for _, extra := range strings.Split(binaryInfo.Extras, ",") {
+				if extra = strings.TrimSpace(extra); extra != "" {
+					result.Extras = append(result.Extras, path.Join(path.Dir(result.Name), extra))
+				}
+			}
+		}
+
+		if len(result.Extras) > 0 {
+			// Extras of extras are part of the same group, don't follow them
+			var err error
+			if extraResults, err = c.install(ctx, result.Extras, installMode{cache: mode.cache, progress: mode.progress, hooks: mode.hooks, transaction: mode.transaction}); err != nil {
+				return extraResults, fmt.Errorf("failed to install the extras of '%s': %v", result.Name, err)
+			}
+			for _, extraResult := range extraResults {
+				record.Extras = append(record.Extras, absPath(extraResult.Path))
+			}
+		}
+	}
+
+	if err := c.recordInstall(result.Path, record); err != nil {
+		return extraResults, fmt.Errorf("failed to record the installation of '%s': %v", result.Name, err)
+	}
+	return extraResults, nil
+}
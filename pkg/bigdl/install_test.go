@@ -0,0 +1,286 @@
+package bigdl
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goccy/go-json"
+)
+
+// testRepo is a repo served by a MirrorServer from a temporary directory, so that no test reaches the network
+type testRepo struct {
+	t      *testing.T
+	dir    string
+	server *httptest.Server
+}
+
+// newTestRepo starts an empty testRepo, which is stopped at the end of the test
+func newTestRepo(t *testing.T) *testRepo {
+	t.Helper()
+	repo := &testRepo{t: t, dir: t.TempDir()}
+	repo.server = httptest.NewServer(NewMirrorServer(repo.dir))
+	t.Cleanup(repo.server.Close)
+	return repo
+}
+
+// publish writes the file at repoPath ("pkg/main", "Baseutils/ls") of the x86_64_Linux directory
+func (repo *testRepo) publish(repoPath string, content []byte) {
+	repo.t.Helper()
+	filePath := filepath.Join(repo.dir, "x86_64_Linux", filepath.FromSlash(repoPath))
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		repo.t.Fatal(err)
+	}
+	if err := os.WriteFile(filePath, content, 0o755); err != nil {
+		repo.t.Fatal(err)
+	}
+}
+
+// describe writes the METADATA.json the MirrorServer takes the descriptions, extras, etc. from
+func (repo *testRepo) describe(binaries ...BinaryInfo) {
+	repo.t.Helper()
+	data, err := json.Marshal(binaries)
+	if err != nil {
+		repo.t.Fatal(err)
+	}
+	repo.publish(MetadataFile, data)
+}
+
+// testEnv holds the directories of a bigdl installation that uses a testRepo
+type testEnv struct {
+	t    *testing.T
+	repo *testRepo
+	dir  string
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	return &testEnv{t: t, repo: newTestRepo(t), dir: t.TempDir()}
+}
+
+// client returns a new Client, which doesn't reuse the metadata fetched by the previous ones
+func (env *testEnv) client() *Client {
+	return env.clientWith(Options{})
+}
+
+// clientWith returns a new Client with the given options, plus the directories and repos of the testEnv
+func (env *testEnv) clientWith(opts Options) *Client {
+	env.t.Helper()
+	opts.InstallDir = filepath.Join(env.dir, "bin")
+	opts.CacheDir = filepath.Join(env.dir, "cache")
+	opts.StateDir = filepath.Join(env.dir, "state")
+	opts.Arch = "amd64_linux"
+	opts.Repositories = MirrorRepositories(env.repo.server.URL)
+	opts.InstallExtras, opts.RecordInstalls = true, true
+	client, err := New(opts)
+	if err != nil {
+		env.t.Fatal(err)
+	}
+	return client
+}
+
+// installed returns the path of the binary in InstallDir
+func (env *testEnv) installed(fileName string) string {
+	return filepath.Join(env.dir, "bin", fileName)
+}
+
+// script returns an executable that CheckBinary accepts on any machine
+func script(output string) []byte {
+	return []byte("#!/bin/sh\necho " + output + "\n")
+}
+
+func assertContent(t *testing.T, filePath string, content []byte) {
+	t.Helper()
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("%s: %v", filePath, err)
+	}
+	if string(data) != string(content) {
+		t.Fatalf("%s contains %q, want %q", filePath, data, content)
+	}
+}
+
+func assertMissing(t *testing.T, filePath string) {
+	t.Helper()
+	if FileExists(filePath) {
+		t.Fatalf("%s should not exist", filePath)
+	}
+}
+
+func TestParseInstallName(t *testing.T) {
+	tests := []struct {
+		name, src, dest string
+	}{
+		{"btop", "btop", "btop"},
+		{"Baseutils/ls", "Baseutils/ls", "ls"},
+		{"toybox/wget:twget", "toybox/wget", "twget"},
+		{"wget:bin/twget", "wget", "twget"},
+		{"wget:", "wget:", "wget:"},
+		{":twget", ":twget", ":twget"},
+	}
+	for _, test := range tests {
+		if src, dest := ParseInstallName(test.name); src != test.src || dest != test.dest {
+			t.Errorf("ParseInstallName(%q) = %q, %q, want %q, %q", test.name, src, dest, test.src, test.dest)
+		}
+	}
+}
+
+func TestInstallUpdateRemove(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t)
+	env.repo.publish("hello", script("v1"))
+
+	if _, err := env.client().Install(ctx, []string{"hello"}); err != nil {
+		t.Fatal(err)
+	}
+	assertContent(t, env.installed("hello"), script("v1"))
+
+	results, err := env.client().Update(ctx, nil, UpdateOptions{})
+	if err != nil || len(results) != 1 || results[0].Status != UpdateUpToDate {
+		t.Fatalf("update without changes: %+v, %v", results, err)
+	}
+
+	env.repo.publish("hello", script("v2"))
+	results, err = env.client().Update(ctx, nil, UpdateOptions{})
+	if err != nil || len(results) != 1 || results[0].Status != UpdateUpdated {
+		t.Fatalf("update: %+v, %v", results, err)
+	}
+	assertContent(t, env.installed("hello"), script("v2"))
+
+	removed := env.client().Remove(ctx, []string{"hello"})
+	if len(removed) != 1 || removed[0].Err != nil {
+		t.Fatalf("remove: %+v", removed)
+	}
+	assertMissing(t, env.installed("hello"))
+}
+
+func TestUpdateKeepsExtras(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t)
+	env.repo.publish("pkg/main", script("main v1"))
+	env.repo.publish("pkg/comp", script("comp"))
+	env.repo.describe(BinaryInfo{Name: "pkg/main", Extras: "comp"})
+
+	if _, err := env.client().Install(ctx, []string{"pkg/main"}); err != nil {
+		t.Fatal(err)
+	}
+	assertContent(t, env.installed("comp"), script("comp"))
+
+	env.repo.publish("pkg/main", script("main v2"))
+	if results, err := env.client().Update(ctx, []string{"main"}, UpdateOptions{}); err != nil || results[0].Status != UpdateUpdated {
+		t.Fatalf("update: %+v, %v", results, err)
+	}
+
+	// The group survives the update, removing the binary removes its companions
+	env.client().Remove(ctx, []string{"main"})
+	assertMissing(t, env.installed("main"))
+	assertMissing(t, env.installed("comp"))
+}
+
+func TestUndoInstallWithExtras(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t)
+	env.repo.publish("pkg/main", script("main"))
+	env.repo.publish("pkg/comp", script("comp"))
+	env.repo.describe(BinaryInfo{Name: "pkg/main", Extras: "comp"})
+
+	// The companion replaces a file of the user, which the undo has to bring back
+	if err := os.MkdirAll(env.installed(""), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(env.installed("comp"), script("mine"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := env.client().Install(ctx, []string{"pkg/main"}); err != nil {
+		t.Fatal(err)
+	}
+	history, err := env.client().History("")
+	if err != nil || len(history) != 2 {
+		t.Fatalf("the install and its extra should be in the history: %+v, %v", history, err)
+	}
+
+	results, err := env.client().Undo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, result := range results {
+		if result.Err != nil {
+			t.Fatalf("undo: %v", result.Err)
+		}
+	}
+	assertMissing(t, env.installed("main"))
+	assertContent(t, env.installed("comp"), script("mine"))
+	// bigdl didn't install the file it brought back
+	if record, recorded := env.client().Installed(env.installed("comp")); recorded {
+		t.Errorf("the restored file of the user is recorded as an install of '%s'", record.Name)
+	}
+}
+
+func TestUndoRemoveRestoresGroup(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t)
+	env.repo.publish("pkg/main", script("main"))
+	env.repo.publish("pkg/comp", script("comp"))
+	env.repo.describe(BinaryInfo{Name: "pkg/main", Extras: "comp"})
+
+	if _, err := env.client().Install(ctx, []string{"pkg/main:renamed"}); err != nil {
+		t.Fatal(err)
+	}
+	env.client().Remove(ctx, []string{"renamed"})
+	assertMissing(t, env.installed("comp"))
+
+	if _, err := env.client().Undo(ctx); err != nil {
+		t.Fatal(err)
+	}
+	assertContent(t, env.installed("renamed"), script("main"))
+	assertContent(t, env.installed("comp"), script("comp"))
+	if record, _ := env.client().Installed(env.installed("renamed")); record.Name != "pkg/main" || len(record.Extras) != 1 {
+		t.Fatalf("the record of the alias wasn't restored: '%s' with extras %q", record.Name, record.Extras)
+	}
+
+	// The group is whole again
+	env.client().Remove(ctx, []string{"renamed"})
+	assertMissing(t, env.installed("comp"))
+}
+
+func TestUpdateArchive(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t)
+	env.repo.publish("rg.tar.gz", tarGz(t, "rg-1/rg", string(script("rg v1")), "rg-1/README", "docs"))
+
+	if _, err := env.client().Install(ctx, []string{"rg.tar.gz"}); err != nil {
+		t.Fatal(err)
+	}
+	assertContent(t, env.installed("rg"), script("rg v1"))
+	assertMissing(t, env.installed("README"))
+
+	verified, err := env.client().Verify(ctx, []string{"rg"})
+	if err != nil || len(verified) != 1 || verified[0].Status != VerifyOK {
+		t.Fatalf("verify: %+v, %v", verified, err)
+	}
+	results, err := env.client().Update(ctx, nil, UpdateOptions{})
+	if err != nil || len(results) != 1 || results[0].Status != UpdateUpToDate {
+		t.Fatalf("update without changes: %+v, %v", results, err)
+	}
+
+	// The binary is extracted again out of the new archive
+	env.repo.publish("rg.tar.gz", tarGz(t, "rg-2/rg", string(script("rg v2")), "rg-2/README", "docs"))
+	results, err = env.client().Update(ctx, nil, UpdateOptions{})
+	if err != nil || len(results) != 1 || results[0].Status != UpdateUpdated {
+		t.Fatalf("update: %+v, %v", results, err)
+	}
+	assertContent(t, env.installed("rg"), script("rg v2"))
+	assertMissing(t, env.installed("README"))
+}
+
+func TestInstallArchiveDuplicateNames(t *testing.T) {
+	env := newTestEnv(t)
+	env.repo.publish("tools.tar.gz", tarGz(t, "amd64/tool", string(script("amd64")), "arm64/tool", string(script("arm64"))))
+
+	if _, err := env.client().Install(context.Background(), []string{"tools.tar.gz"}); err == nil {
+		t.Fatal("installing two entries named tool should fail")
+	}
+	assertMissing(t, env.installed("tool"))
+}
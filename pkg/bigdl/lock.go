@@ -0,0 +1,57 @@
+// lock.go // This file implements the global lock that keeps concurrent bigdl runs from modifying the same files //>
+package bigdl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// ErrLocked is returned by Lock when another instance of bigdl holds the lock and waiting wasn't allowed
+var ErrLocked = errors.New("another instance of bigdl is running")
+
+// Lock takes an exclusive advisory lock (flock) on the lock file of StateDir. If another bigdl holds it, Lock waits for it to finish (or for ctx to be cancelled), or fails with ErrLocked if wait is false. The returned function releases the lock.
+func (c *Client) Lock(ctx context.Context, wait bool) (func(), error) {
+	if err := os.MkdirAll(c.opts.StateDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %v", err)
+	}
+
+	lockPath := filepath.Join(c.opts.StateDir, "bigdl.lock")
+	lockFile, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %v", err)
+	}
+
+	err = unix.Flock(int(lockFile.Fd()), unix.LOCK_EX|unix.LOCK_NB)
+	if err == unix.EWOULDBLOCK {
+		if !wait {
+			lockFile.Close()
+			return nil, fmt.Errorf("error: %w (%s is locked)", ErrLocked, lockPath)
+		}
+		c.logf("Waiting for another instance of bigdl to finish...\n")
+		// A blocking flock can't be interrupted, poll instead
+		for err == unix.EWOULDBLOCK {
+			select {
+			case <-ctx.Done():
+				lockFile.Close()
+				return nil, ctx.Err()
+			case <-time.After(100 * time.Millisecond):
+			}
+			err = unix.Flock(int(lockFile.Fd()), unix.LOCK_EX|unix.LOCK_NB)
+		}
+	}
+	if err != nil {
+		lockFile.Close()
+		return nil, fmt.Errorf("failed to lock %s: %v", lockPath, err)
+	}
+
+	return func() {
+		_ = unix.Flock(int(lockFile.Fd()), unix.LOCK_UN)
+		lockFile.Close()
+	}, nil
+}
@@ -0,0 +1,53 @@
+package bigdl
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLock(t *testing.T) {
+	ctx := context.Background()
+	client := newArchClient(t, "amd64_linux")
+
+	unlock, err := client.Lock(ctx, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// flock(2) locks are held by open files, so another Lock of the same process conflicts with the first like another bigdl would
+	if _, err := client.Lock(ctx, false); !errors.Is(err, ErrLocked) {
+		t.Fatalf("Lock without waiting = %v, want ErrLocked", err)
+	}
+	timeout, cancel := context.WithTimeout(ctx, 150*time.Millisecond)
+	defer cancel()
+	if _, err := client.Lock(timeout, true); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Lock waiting past the deadline = %v, want context.DeadlineExceeded", err)
+	}
+
+	// A waiting Lock gets the lock once it is released
+	locked := make(chan error, 1)
+	go func() {
+		unlock, err := client.Lock(ctx, true)
+		if err == nil {
+			unlock()
+		}
+		locked <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+	unlock()
+	select {
+	case err := <-locked:
+		if err != nil {
+			t.Fatalf("Lock after the release: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Lock kept waiting after the release")
+	}
+
+	unlock, err = client.Lock(ctx, false)
+	if err != nil {
+		t.Fatalf("Lock once every lock is released: %v", err)
+	}
+	unlock()
+}
@@ -0,0 +1,276 @@
+// mirror.go // This file implements the replication of a subset of the repos into a directory that MirrorServer (or any HTTP server) can serve //>
+package bigdl
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-json"
+)
+
+// MirrorStatus is the outcome of mirroring a binary
+type MirrorStatus int
+
+const (
+	MirrorUnchanged  MirrorStatus = iota // The mirrored file already matched the repo's
+	MirrorDownloaded                     // The binary was downloaded and verified
+	MirrorFailed                         // The binary could not be downloaded, or didn't match its hash
+)
+
+// MirrorResult describes the outcome of mirroring a binary with Mirror
+type MirrorResult struct {
+	Name    string // Repository-qualified, e.g: "Baseutils/ls"
+	Path    string // Where the binary is in the mirror
+	Status  MirrorStatus
+	Message string // Describes the Status. e.g: "Mirrored ls, its hash matches."
+	Err     error  // Set when Status is MirrorFailed
+}
+
+// MirrorOptions configures Mirror
+type MirrorOptions struct {
+	// Filters select the binaries to mirror: names ("btop"), globs ("*grep") or topics ("topic:networking"), matched against the name and the base name of the binaries. If empty, every binary is mirrored
+	Filters []string
+	// BaseURL is the address the mirror will be served at, which the download_url of its metadata point to. e.g: "http://192.168.1.10:8080"
+	BaseURL string
+	// Progress, if set, is called with the number of binaries processed so far, their total and the result of the last one
+	Progress func(done, total int, result MirrorResult)
+}
+
+// mirroredBinary is a binary selected by Mirror, along with the repo directory (relative to the one of the architecture) it goes to
+type mirroredBinary struct {
+	info    BinaryInfo
+	repo    int
+	repoDir string
+}
+
+// Mirror downloads the binaries of the repos that match opts.Filters into outDir, with the layout of the repos (see MirrorServer), and writes the METADATA.json of each repo with download_url pointing to opts.BaseURL.
+// Files that already match the hash of the repos are not downloaded again, and downloads that don't match it are discarded. Only the DefaultRepositories, or the repos of another mirror, can be mirrored.
+// Nothing is written if no binary matches the filters. If ctx is cancelled, the results of the binaries processed so far are returned along with ctx.Err(), and the metadata describes the binaries mirrored so far.
+func (c *Client) Mirror(ctx context.Context, outDir string, opts MirrorOptions) ([]MirrorResult, error) {
+	selected, err := c.selectMirrored(ctx, opts.Filters)
+	if err != nil || len(selected) == 0 {
+		return nil, err
+	}
+
+	archDir := filepath.Join(outDir, c.arch[0])
+	mirrored := make(map[string][]BinaryInfo) // The metadata of each repo directory
+	var results []MirrorResult
+	for _, binary := range selected {
+		if ctx.Err() != nil {
+			break
+		}
+		result := c.mirrorBinary(ctx, binary, filepath.Join(archDir, filepath.FromSlash(binary.repoDir)))
+		if result.Status != MirrorFailed {
+			mirrored[binary.repoDir] = append(mirrored[binary.repoDir], binary.info)
+		}
+		results = append(results, result)
+		if opts.Progress != nil {
+			opts.Progress(len(results), len(selected), result)
+		}
+	}
+
+	if err := c.writeMirrorMetadata(archDir, opts.BaseURL, mirrored); err != nil {
+		return results, err
+	}
+	return results, ctx.Err()
+}
+
+// selectMirrored returns the binaries of the repos that match the filters, in order of priority
+func (c *Client) selectMirrored(ctx context.Context, filters []string) ([]mirroredBinary, error) {
+	subrepos := mirrorSubrepos()
+	seen := make(map[string]struct{})
+	var selected []mirroredBinary
+	for i, repo := range c.repos {
+		repoDir, mirrorable := subrepos[repo.Name]
+		if repo.Name == DefaultRepositories[0].Name {
+			repoDir, mirrorable = "", true
+		}
+		if !mirrorable {
+			c.logf("Skipping %s, it isn't one of the DefaultRepositories\n", repo.Name)
+			continue
+		}
+
+		catalogue, err := c.loadCatalogue(ctx, repo.MetadataURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch metadata from %s: %v", repo.MetadataURL, err)
+		}
+		for j, binInfo := range catalogue.Binaries {
+			// The metadata of the first repo describes every repo, each binary is mirrored with its own
+			if catalogue.Repos[j] != i || binInfo.Name == "" || IsExcluded(binInfo.Name) || !matchesFilters(binInfo, filters) {
+				continue
+			}
+			if _, duplicated := seen[c.QualifiedName(i, binInfo.Name)]; duplicated {
+				continue
+			}
+			seen[c.QualifiedName(i, binInfo.Name)] = struct{}{}
+			selected = append(selected, mirroredBinary{info: binInfo, repo: i, repoDir: repoDir})
+		}
+	}
+	return selected, nil
+}
+
+// matchesFilters reports whether the binary matches any of the filters (see MirrorOptions.Filters), or if there are none
+func matchesFilters(binInfo BinaryInfo, filters []string) bool {
+	if len(filters) == 0 {
+		return true
+	}
+	for _, filter := range filters {
+		if topic, isTopic := strings.CutPrefix(filter, "topic:"); isTopic {
+			for _, binTopic := range strings.Split(binInfo.Topics, ",") {
+				if strings.EqualFold(strings.TrimSpace(binTopic), topic) {
+					return true
+				}
+			}
+			continue
+		}
+		for _, name := range []string{binInfo.Name, path.Base(binInfo.Name)} {
+			if matched, _ := path.Match(filter, name); matched || filter == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// mirrorBinary downloads the binary to repoDir, unless the file there already matches the hash of the repo. The download is verified before replacing the file.
+func (c *Client) mirrorBinary(ctx context.Context, binary mirroredBinary, repoDir string) MirrorResult {
+	name := c.QualifiedName(binary.repo, binary.info.Name)
+	mirrorPath := filepath.Join(repoDir, filepath.FromSlash(binary.info.Name))
+	result := MirrorResult{Name: name, Path: mirrorPath}
+	fail := func(err error) MirrorResult {
+		result.Status, result.Message, result.Err = MirrorFailed, fmt.Sprintf("Failed to mirror %s.", name), err
+		return result
+	}
+
+	expected := binary.info.Checksum()
+	if FileExists(mirrorPath) && !expected.Empty() {
+		if unchanged, err := (&fileChecksum{path: mirrorPath}).matches(expected); err == nil && unchanged {
+			result.Status, result.Message = MirrorUnchanged, fmt.Sprintf("%s is up to date.", name)
+			return result
+		}
+	}
+	if binary.info.Source == "" {
+		return fail(fmt.Errorf("error: the metadata of '%s' has no download_url", name))
+	}
+
+	if err := os.MkdirAll(filepath.Dir(mirrorPath), 0o755); err != nil {
+		return fail(fmt.Errorf("failed to create directory: %v", err))
+	}
+	// The temporary file is in the mirror itself, so that it can be renamed into place. MirrorServer ignores .tmp files
+	out, err := os.CreateTemp(filepath.Dir(mirrorPath), filepath.Base(mirrorPath)+".*.tmp")
+	if err != nil {
+		return fail(fmt.Errorf("failed to create temporary file: %v", err))
+	}
+	defer out.Close()
+	defer os.Remove(out.Name())
+
+	if err := c.downloadTo(ctx, binary.info.Source, out, false); err != nil {
+		return fail(err)
+	}
+	if err := out.Close(); err != nil {
+		return fail(fmt.Errorf("failed to close temporary file: %v", err))
+	}
+
+	result.Message = fmt.Sprintf("Mirrored %s, but the repos don't publish a hash for it.", name)
+	if !expected.Empty() {
+		downloaded := &fileChecksum{path: out.Name()}
+		matches, err := downloaded.matches(expected)
+		if err != nil {
+			return fail(err)
+		}
+		if !matches {
+			return fail(downloaded.mismatch(name, expected))
+		}
+		result.Message = fmt.Sprintf("Mirrored %s, its hash matches.", name)
+	}
+
+	if err := os.Chmod(out.Name(), 0o755); err != nil {
+		return fail(fmt.Errorf("failed to set executable bit: %v", err))
+	}
+	if err := os.Rename(out.Name(), mirrorPath); err != nil {
+		return fail(fmt.Errorf("failed to move binary to destination: %v", err))
+	}
+	result.Status = MirrorDownloaded
+	return result
+}
+
+// mirrorPath returns the path of the binary in the mirror, relative to its root and slash-separated. e.g: "x86_64_Linux/Baseutils/ls"
+func (c *Client) mirrorPath(repoDir, binaryName string) string {
+	return path.Join(c.arch[0], repoDir, binaryName)
+}
+
+// writeMirrorMetadata writes the METADATA.json of each repo directory of archDir, with the binaries mirrored now and the ones mirrored by previous runs that are still there. Their download_url point to baseURL.
+// Like the one of the first repo, the METADATA.json of archDir describes the binaries of every repo.
+func (c *Client) writeMirrorMetadata(archDir, baseURL string, mirrored map[string][]BinaryInfo) error {
+	var subrepoDirs []string
+	for _, repoDir := range mirrorSubrepos() {
+		subrepoDirs = append(subrepoDirs, repoDir)
+	}
+	sort.Strings(subrepoDirs)
+
+	index := c.mergeMirrorMetadata(archDir, "", baseURL, mirrored[""])
+	for _, repoDir := range subrepoDirs {
+		metadata := c.mergeMirrorMetadata(archDir, repoDir, baseURL, mirrored[repoDir])
+		if len(metadata) == 0 {
+			continue
+		}
+		if err := writeMetadata(filepath.Join(archDir, repoDir, MetadataFile), metadata); err != nil {
+			return err
+		}
+		index = append(index, metadata...)
+	}
+	if len(index) == 0 {
+		return nil
+	}
+	return writeMetadata(filepath.Join(archDir, MetadataFile), index)
+}
+
+// mergeMirrorMetadata returns the metadata of the repo directory repoDir: the binaries that were just mirrored, followed by the ones its METADATA.json already described that are still in the mirror. Every download_url is rewritten to point to baseURL.
+func (c *Client) mergeMirrorMetadata(archDir, repoDir, baseURL string, mirrored []BinaryInfo) []BinaryInfo {
+	metadata := append([]BinaryInfo(nil), mirrored...)
+	seen := make(map[string]struct{})
+	for _, binInfo := range mirrored {
+		seen[binInfo.Name] = struct{}{}
+	}
+
+	var existing []BinaryInfo
+	if data, err := os.ReadFile(filepath.Join(archDir, repoDir, MetadataFile)); err == nil {
+		json.Unmarshal(data, &existing)
+	}
+	for _, binInfo := range existing {
+		// The METADATA.json of archDir describes the binaries of the other repos too, those are left to their own metadata
+		mirrorPath := c.mirrorPath(repoDir, binInfo.Name)
+		if _, duplicated := seen[binInfo.Name]; duplicated || !strings.HasSuffix(binInfo.Source, (&url.URL{Path: "/" + mirrorPath}).EscapedPath()) {
+			continue
+		}
+		if !FileExists(filepath.Join(filepath.Dir(archDir), filepath.FromSlash(mirrorPath))) {
+			continue
+		}
+		seen[binInfo.Name] = struct{}{}
+		metadata = append(metadata, binInfo)
+	}
+
+	for i := range metadata {
+		metadata[i].Source = strings.TrimSuffix(baseURL, "/") + (&url.URL{Path: "/" + c.mirrorPath(repoDir, metadata[i].Name)}).EscapedPath()
+	}
+	return metadata
+}
+
+// writeMetadata writes the metadata to metadataPath, replacing the file atomically
+func writeMetadata(metadataPath string, metadata []BinaryInfo) error {
+	data, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return err
+	}
+	tempFile := metadataPath + ".tmp"
+	if err := os.WriteFile(tempFile, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %v", tempFile, err)
+	}
+	return os.Rename(tempFile, metadataPath)
+}
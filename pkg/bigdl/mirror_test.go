@@ -0,0 +1,29 @@
+package bigdl
+
+import "testing"
+
+func TestMatchesFilters(t *testing.T) {
+	binInfo := BinaryInfo{Name: "toybox/wget", Topics: "networking, Downloaders"}
+	tests := []struct {
+		filters []string
+		want    bool
+	}{
+		{nil, true},
+		{[]string{"wget"}, true},
+		{[]string{"toybox/wget"}, true},
+		{[]string{"*get"}, true},
+		{[]string{"toybox/*"}, true},
+		{[]string{"curl", "w*"}, true},
+		{[]string{"topic:downloaders"}, true},
+		{[]string{"topic:networking"}, true},
+		{[]string{"curl"}, false},
+		{[]string{"toybox"}, false},
+		{[]string{"topic:editors"}, false},
+		{[]string{"topic:wget"}, false},
+	}
+	for _, test := range tests {
+		if got := matchesFilters(binInfo, test.filters); got != test.want {
+			t.Errorf("matchesFilters(%q) = %v, want %v", test.filters, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+// remove.go // This file implements the removal of installed binaries, along with the companions of their group and their records //>
+package bigdl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// RemoveResult describes the outcome of removing a binary from InstallDir
+type RemoveResult struct {
+	Name string // Base name of the removed file
+	Path string // Path of the removed file
+	Err  error  // Why it could not be removed. It wraps os.ErrNotExist if it wasn't there
+	// HookErrors holds the failures of the pre-remove and post-remove hooks. They don't stop the removal
+	HookErrors []error
+}
+
+// Remove removes the binaries from InstallDir, along with the companion binaries that were installed as a group with them. A result is returned for every file, including the extras.
+func (c *Client) Remove(ctx context.Context, binariesToRemove []string) []RemoveResult {
+	transaction := newTransaction()
+	defer c.pruneBackups()
+	var results []RemoveResult
+	for _, binaryName := range binariesToRemove {
+		installPath := filepath.Join(c.opts.InstallDir, filepath.Base(binaryName))
+		record, _ := c.Installed(installPath)
+		result := c.removeInstalled(ctx, transaction, installPath)
+		results = append(results, result)
+		if result.Err != nil {
+			continue
+		}
+
+		// Remove the companion binaries that were installed as a group with it
+		for _, extraPath := range record.Extras {
+			if FileExists(extraPath) {
+				results = append(results, c.removeInstalled(ctx, transaction, extraPath))
+			}
+		}
+	}
+	return results
+}
+
+// removeInstalled removes the file at installPath and its record, running the remove hooks around it.
+func (c *Client) removeInstalled(ctx context.Context, transaction, installPath string) RemoveResult {
+	result := RemoveResult{Name: filepath.Base(installPath), Path: installPath}
+
+	binaryName := c.CatalogueName(installPath)
+	record := c.installedRecord(installPath)
+	target := c.installedTarget(ctx, binaryName, installPath)
+	if FileExists(installPath) {
+		result.HookErrors = c.runHooks(ctx, PreRemove, target)
+		// The post-remove hooks and the history get the SHA256 of the file that was removed
+		target.sha256()
+	}
+
+	// Removals can be undone
+	c.backup(installPath)
+	if err := os.Remove(installPath); err != nil {
+		if os.IsNotExist(err) {
+			result.Err = fmt.Errorf("'%s' does not exist in %s: %w", result.Name, filepath.Dir(installPath), os.ErrNotExist)
+		} else {
+			result.Err = fmt.Errorf("failed to remove '%s' from %s. %v", result.Name, filepath.Dir(installPath), err)
+			c.recordChange(ctx, transaction, "remove", binaryName, installPath, target.sha256(), record, result.Err)
+		}
+		return result
+	}
+	c.recordChange(ctx, transaction, "remove", binaryName, installPath, target.sha256(), record, nil)
+	if err := c.forgetInstall(installPath); err != nil {
+		c.logf("Warning: %v\n", err)
+	}
+	result.HookErrors = append(result.HookErrors, c.runHooks(ctx, PostRemove, target)...)
+	return result
+}
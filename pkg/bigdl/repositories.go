@@ -0,0 +1,172 @@
+// repositories.go // This file implements the architectures and repos bigdl supports, repository-qualified names ("Baseutils/ls") and the priority between repos //>
+package bigdl
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SupportedArchs maps a GOARCH_GOOS pair to the names the repos use for that architecture
+var SupportedArchs = map[string][3]string{
+	"amd64_linux":   {"x86_64_Linux", "x86_64", "x86_64-Linux"},
+	"arm64_linux":   {"aarch64_arm64_Linux", "aarch64_arm64", "aarch64-Linux"},
+	"arm64_android": {"arm64_v8a_Android", "arm64_v8a_Android", "arm64-v8a-Android"},
+	"riscv64_linux": {"riscv64_Linux", "riscv64", "riscv64-Linux"},
+	"arm_linux":     {"armv7_Linux", "armv7", "armv7-Linux"},
+	// "amd64_windows": {"x64_Windows", "x64_Windows", "AMD64-Windows_NT"}, // not yet supported. Not sure if it will ever be.
+}
+
+// RepositoryTemplate describes a repository. The "%s" in its URLs is replaced by the name the repository gives to the selected architecture
+type RepositoryTemplate struct {
+	Name        string
+	URL         string
+	MetadataURL string
+	// Archs maps the first name of SupportedArchs to the name this repository uses for it, only the listed architectures are used. If nil, every architecture is assumed to be published under its first name
+	Archs map[string]string
+}
+
+// DefaultRepositories are the repos used when Options.Repositories is nil, in order of preference. The first one provides the metadata of every repo
+var DefaultRepositories = []RepositoryTemplate{
+	{Name: "Toolpacks", URL: "https://bin.ajam.dev/%s/", MetadataURL: "https://bin.ajam.dev/%s/METADATA.json"},
+	{Name: "Baseutils", URL: "https://bin.ajam.dev/%s/Baseutils/", MetadataURL: "https://bin.ajam.dev/%s/Baseutils/METADATA.json"},
+	//{Name: "Handyscripts", URL: "https://raw.githubusercontent.com/xplshn/Handyscripts/master/", MetadataURL: "https://api.github.com/repos/xplshn/Handyscripts/contents"},
+}
+
+// mirrorSubrepos returns the directories of the DefaultRepositories that live below the first one, relative to it. e.g: "Baseutils"
+func mirrorSubrepos() map[string]string {
+	subrepos := make(map[string]string)
+	for _, repo := range DefaultRepositories[1:] {
+		if dir, found := strings.CutPrefix(repo.URL, DefaultRepositories[0].URL); found && dir != "" {
+			subrepos[repo.Name] = strings.Trim(dir, "/")
+		}
+	}
+	return subrepos
+}
+
+// MirrorRepositories returns the repositories of a mirror served by MirrorServer (or any server with the same layout) at baseURL. e.g: "http://192.168.1.10:8080"
+func MirrorRepositories(baseURL string) []RepositoryTemplate {
+	baseURL = strings.TrimSuffix(baseURL, "/") + "/%s/"
+	repos := []RepositoryTemplate{{Name: DefaultRepositories[0].Name, URL: baseURL, MetadataURL: baseURL + MetadataFile}}
+	subrepos := mirrorSubrepos()
+	for _, repo := range DefaultRepositories[1:] {
+		if dir, found := subrepos[repo.Name]; found {
+			repos = append(repos, RepositoryTemplate{Name: repo.Name, URL: baseURL + dir + "/", MetadataURL: baseURL + dir + "/" + MetadataFile})
+		}
+	}
+	return repos
+}
+
+// setArchitecture resolves the names of the architecture and the repos that publish binaries for it. arch is either in GOARCH_GOOS format ("arm64_linux") or the name the repos use for it ("aarch64_arm64_Linux")
+func (c *Client) setArchitecture(arch string) error {
+	validatedArch, ok := SupportedArchs[arch]
+	if !ok {
+		for _, names := range SupportedArchs {
+			if names[0] == arch {
+				validatedArch, ok = names, true
+				break
+			}
+		}
+	}
+	if !ok {
+		return fmt.Errorf("Unsupported architecture: %s", arch)
+	}
+	c.arch = validatedArch
+
+	c.repos, c.rnMetadataURL = nil, ""
+	for _, repo := range prioritizeRepositories(c.opts.Repositories, c.opts.RepoPriority) {
+		repoArch := c.arch[0]
+		if repo.Archs != nil {
+			if repoArch, ok = repo.Archs[c.arch[0]]; !ok {
+				continue // This repository does not publish binaries for the selected architecture
+			}
+		}
+		c.repos = append(c.repos, Repository{
+			Name: repo.Name,
+			URL:  fmt.Sprintf(repo.URL, repoArch),
+			// Binaries that are available in the Repositories but aren't described in any metadata will not be updated, nor listed with `info` nor `list`
+			MetadataURL: fmt.Sprintf(repo.MetadataURL, repoArch),
+		})
+	}
+	if len(c.repos) == 0 {
+		return fmt.Errorf("No repository provides binaries for %s", c.arch[0])
+	}
+
+	// The index is a concatenation of all metadata in the different repos, it also contains sha256 checksums. It is provided by the first repo declared, regardless of the priority
+	for _, repo := range c.opts.Repositories {
+		if i := c.repoIndex(repo.Name); i != -1 {
+			c.rnMetadataURL = c.repos[i].MetadataURL
+			break
+		}
+	}
+	return nil
+}
+
+// prioritizeRepositories returns the templates sorted by priority. Repos that aren't mentioned there keep their order, after the mentioned ones.
+func prioritizeRepositories(templates []RepositoryTemplate, priority []string) []RepositoryTemplate {
+	rank := func(repo RepositoryTemplate) int {
+		for i, name := range priority {
+			if strings.EqualFold(strings.TrimSpace(name), repo.Name) {
+				return i
+			}
+		}
+		return len(priority)
+	}
+
+	sorted := append([]RepositoryTemplate(nil), templates...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return rank(sorted[i]) < rank(sorted[j])
+	})
+	return sorted
+}
+
+// repoIndex returns the index of the repo with the given name (case-insensitive), or -1
+func (c *Client) repoIndex(name string) int {
+	for i, repo := range c.repos {
+		if strings.EqualFold(repo.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// SplitRepoName splits a repository-qualified name ("Baseutils/ls") into the index of the repo in Repositories() and the name of the binary. The index is -1 if the name is not qualified.
+func (c *Client) SplitRepoName(binaryName string) (int, string) {
+	repoName, name, found := strings.Cut(binaryName, "/")
+	if !found {
+		return -1, binaryName
+	}
+	if i := c.repoIndex(repoName); i != -1 {
+		return i, name
+	}
+	return -1, binaryName
+}
+
+// repoOf returns the index of the repo that serves the given download URL, or -1. The longest matching repo URL wins, since repos can be nested (Baseutils is inside of Toolpacks).
+func (c *Client) repoOf(downloadURL string) int {
+	match := -1
+	for i, repo := range c.repos {
+		if strings.HasPrefix(downloadURL, repo.URL) && (match == -1 || len(repo.URL) > len(c.repos[match].URL)) {
+			match = i
+		}
+	}
+	return match
+}
+
+// QualifiedName prefixes the binary name with the name of the repo at index repoIndex, if there's one.
+func (c *Client) QualifiedName(repoIndex int, binaryName string) string {
+	if repoIndex < 0 || repoIndex >= len(c.repos) {
+		return binaryName
+	}
+	return c.repos[repoIndex].Name + "/" + binaryName
+}
+
+// metadataRepo returns the index of the repo that serves the given metadata URL, or -1
+func (c *Client) metadataRepo(metadataURL string) int {
+	for i, repo := range c.repos {
+		if repo.MetadataURL == metadataURL {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,89 @@
+package bigdl
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestPrioritizeRepositories(t *testing.T) {
+	templates := []RepositoryTemplate{{Name: "Toolpacks"}, {Name: "Baseutils"}, {Name: "Handyscripts"}}
+	tests := []struct {
+		priority []string
+		want     []string
+	}{
+		{nil, []string{"Toolpacks", "Baseutils", "Handyscripts"}},
+		{[]string{"Handyscripts"}, []string{"Handyscripts", "Toolpacks", "Baseutils"}},
+		{[]string{" baseutils ", "Handyscripts"}, []string{"Baseutils", "Handyscripts", "Toolpacks"}},
+		{[]string{"Unknown", "Baseutils"}, []string{"Baseutils", "Toolpacks", "Handyscripts"}},
+	}
+	for _, test := range tests {
+		sorted := prioritizeRepositories(templates, test.priority)
+		for i, repo := range sorted {
+			if repo.Name != test.want[i] {
+				t.Errorf("prioritizeRepositories(%q) = %v, want %v", test.priority, sorted, test.want)
+				break
+			}
+		}
+	}
+	if templates[0].Name != "Toolpacks" {
+		t.Error("prioritizeRepositories should not reorder its argument")
+	}
+}
+
+func TestCataloguePriority(t *testing.T) {
+	ctx := context.Background()
+	env := newTestEnv(t)
+	env.repo.publish("hello", script("Toolpacks"))
+	env.repo.publish("Baseutils/hello", script("Baseutils"))
+	env.repo.publish("Baseutils/ls", script("ls"))
+
+	tests := []struct {
+		priority []string
+		repo     string // The repo "hello" resolves to
+		list     []string
+	}{
+		{nil, "Toolpacks", []string{"Toolpacks/hello", "Baseutils/hello", "Baseutils/ls"}},
+		{[]string{"Baseutils"}, "Baseutils", []string{"Baseutils/hello", "Baseutils/ls", "Toolpacks/hello"}},
+	}
+	for _, test := range tests {
+		client := env.clientWith(Options{RepoPriority: test.priority})
+		catalogue, err := client.Catalogue(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		binInfo, found := catalogue.Lookup("hello")
+		if !found || binInfo.Source != env.repo.server.URL+"/x86_64_Linux/"+subrepoPath(test.repo)+"hello" {
+			t.Errorf("with priority %q, hello = %+v, want the one of %s", test.priority, binInfo, test.repo)
+		}
+		// Qualified names always resolve to their repo, and binaries only one repo has to it
+		for _, name := range []string{"Toolpacks/hello", "Baseutils/hello", "Baseutils/ls", "ls"} {
+			if _, found := catalogue.Lookup(name); !found {
+				t.Errorf("with priority %q, %s is missing from the catalogue", test.priority, name)
+			}
+		}
+		if _, found := catalogue.Lookup("Toolpacks/ls"); found {
+			t.Errorf("with priority %q, ls is attributed to Toolpacks", test.priority)
+		}
+
+		// Every listed name can be installed
+		list, err := client.List(ctx)
+		if err != nil || !reflect.DeepEqual(list, test.list) {
+			t.Errorf("with priority %q, List() = %q, %v, want %q", test.priority, list, err, test.list)
+		}
+		for _, name := range list {
+			if _, err := client.FindURL(ctx, name); err != nil {
+				t.Errorf("with priority %q, %s is listed but can't be found: %v", test.priority, name, err)
+			}
+		}
+	}
+}
+
+// subrepoPath returns the directory of the repo in a mirror, with a trailing slash. It is empty for the first repo
+func subrepoPath(repoName string) string {
+	if dir, found := mirrorSubrepos()[repoName]; found {
+		return dir + "/"
+	}
+	return ""
+}
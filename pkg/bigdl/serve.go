@@ -0,0 +1,210 @@
+// serve.go // This file implements the HTTP server of mirrors, which other bigdl clients can use as their repos //>
+package bigdl
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-json"
+)
+
+// MetadataFile is the name of the file that describes the binaries of a repo
+const MetadataFile = "METADATA.json"
+
+// MirrorServer serves a directory over HTTP with the layout of the repos: <arch>/<binary>, and <arch>/<dir>/<binary> for the repos that live below the first one (e.g: Baseutils).
+// The METADATA.json of every repo is generated from the files that are actually there. The METADATA.json found in the directory, if any, only provides the descriptions, versions, etc.
+type MirrorServer struct {
+	dir         string
+	files       http.Handler
+	hashesMutex sync.Mutex
+	hashes      map[string]hashedFile
+}
+
+// hashedFile is the checksum of a mirrored file, cached until the file changes
+type hashedFile struct {
+	size    int64
+	modTime time.Time
+	sum     Checksum
+}
+
+// NewMirrorServer returns a MirrorServer for the mirror at dir
+func NewMirrorServer(dir string) *MirrorServer {
+	return &MirrorServer{
+		dir:    dir,
+		files:  http.FileServer(http.Dir(dir)),
+		hashes: make(map[string]hashedFile),
+	}
+}
+
+// ServeHTTP serves the generated metadata of the repos, and the files of the mirror
+func (s *MirrorServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	repoPath, isMetadata := strings.CutSuffix(path.Clean(r.URL.Path), "/"+MetadataFile)
+	if !isMetadata || !s.isRepo(repoPath) {
+		s.files.ServeHTTP(w, r)
+		return
+	}
+
+	// The binaries are downloaded from wherever the clients reached the server
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	metadata, err := s.Metadata(repoPath, scheme+"://"+r.Host)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	data, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// isRepo reports whether repoPath ("/x86_64_Linux" or "/x86_64_Linux/Baseutils") is the directory of a repo in the mirror. The repos below the first one exist as soon as the architecture's directory does, even if nothing of them was mirrored.
+func (s *MirrorServer) isRepo(repoPath string) bool {
+	parts := strings.Split(strings.Trim(repoPath, "/"), "/")
+	switch {
+	case len(parts) == 1 && parts[0] != "":
+	case len(parts) == 2 && s.isSubrepo(parts[1]):
+	default:
+		return false
+	}
+	info, err := os.Stat(filepath.Join(s.dir, filepath.FromSlash(parts[0])))
+	return err == nil && info.IsDir()
+}
+
+// isSubrepo reports whether dir is the directory of a repo that lives below the first one
+func (s *MirrorServer) isSubrepo(dir string) bool {
+	for _, subrepo := range mirrorSubrepos() {
+		if subrepo == dir {
+			return true
+		}
+	}
+	return false
+}
+
+// Metadata generates the metadata of the repo at repoPath ("x86_64_Linux" or "x86_64_Linux/Baseutils"), with download_url pointing to baseURL.
+// Like the one of the first repo, the metadata of an architecture's directory describes the binaries of the repos below it too.
+func (s *MirrorServer) Metadata(repoPath, baseURL string) ([]BinaryInfo, error) {
+	repoPath = strings.Trim(repoPath, "/")
+	archDir, _, _ := strings.Cut(repoPath, "/")
+	kept := make(map[string]map[string]BinaryInfo) // The metadata found in each repo directory, by name
+
+	metadata := []BinaryInfo{}
+	if _, err := os.Stat(filepath.Join(s.dir, filepath.FromSlash(repoPath))); os.IsNotExist(err) {
+		return metadata, nil
+	}
+	err := filepath.WalkDir(filepath.Join(s.dir, filepath.FromSlash(repoPath)), func(filePath string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(entry.Name(), ".") && filePath != filepath.Join(s.dir, filepath.FromSlash(repoPath)) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() || entry.Name() == MetadataFile || strings.HasSuffix(entry.Name(), ".tmp") {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(s.dir, filePath)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+
+		// Binaries are named after their path in the repo that holds them
+		binaryRepo, name := archDir, strings.TrimPrefix(relPath, archDir+"/")
+		if dir, rest, found := strings.Cut(name, "/"); found && s.isSubrepo(dir) {
+			binaryRepo, name = archDir+"/"+dir, rest
+		}
+		if _, loaded := kept[binaryRepo]; !loaded {
+			kept[binaryRepo] = s.keptMetadata(binaryRepo)
+		}
+
+		binInfo, err := s.describe(filePath, kept[binaryRepo][name])
+		if err != nil {
+			return err
+		}
+		binInfo.Name = name
+		binInfo.Source = strings.TrimSuffix(baseURL, "/") + (&url.URL{Path: "/" + relPath}).EscapedPath()
+		metadata = append(metadata, binInfo)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate the metadata of %s: %v", repoPath, err)
+	}
+	return metadata, nil
+}
+
+// keptMetadata reads the METADATA.json of the repo directory at repoPath, indexed by name. It is empty if there's none.
+func (s *MirrorServer) keptMetadata(repoPath string) map[string]BinaryInfo {
+	kept := make(map[string]BinaryInfo)
+	data, err := os.ReadFile(filepath.Join(s.dir, filepath.FromSlash(repoPath), MetadataFile))
+	if err != nil {
+		return kept
+	}
+	var binaries []BinaryInfo
+	if json.Unmarshal(data, &binaries) == nil {
+		for _, binInfo := range binaries {
+			kept[binInfo.Name] = binInfo
+		}
+	}
+	return kept
+}
+
+// describe fills the size, hashes and build date of binInfo from the file at filePath
+func (s *MirrorServer) describe(filePath string, binInfo BinaryInfo) (BinaryInfo, error) {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return binInfo, err
+	}
+
+	s.hashesMutex.Lock()
+	hashed, cached := s.hashes[filePath]
+	s.hashesMutex.Unlock()
+	if !cached || hashed.size != info.Size() || !hashed.modTime.Equal(info.ModTime()) {
+		hashed = hashedFile{size: info.Size(), modTime: info.ModTime()}
+		if hashed.sum.SHA256, err = SHA256File(filePath); err != nil {
+			return binInfo, err
+		}
+		if hashed.sum.B3SUM, err = B3SUMFile(filePath); err != nil {
+			return binInfo, err
+		}
+		s.hashesMutex.Lock()
+		s.hashes[filePath] = hashed
+		s.hashesMutex.Unlock()
+	}
+
+	binInfo.Size = formatSize(info.Size())
+	binInfo.SHA256, binInfo.B3SUM = hashed.sum.SHA256, hashed.sum.B3SUM
+	if binInfo.ModTime == "" {
+		binInfo.ModTime = info.ModTime().UTC().Format("2006-01-02T15:04:05")
+	}
+	return binInfo, nil
+}
+
+// formatSize formats a size the way the metadata of the repos does. e.g: "3.73 MB"
+func formatSize(size int64) string {
+	units := []string{"B", "kB", "MB", "GB", "TB"}
+	value, unit := float64(size), 0
+	for value >= 1000 && unit < len(units)-1 {
+		value /= 1000
+		unit++
+	}
+	if unit == 0 {
+		return fmt.Sprintf("%d B", size)
+	}
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.2f", value), "0"), ".") + " " + units[unit]
+}
@@ -0,0 +1,24 @@
+package bigdl
+
+import "testing"
+
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		size int64
+		want string
+	}{
+		{0, "0 B"},
+		{999, "999 B"},
+		{1000, "1 kB"},
+		{1500, "1.5 kB"},
+		{3730000, "3.73 MB"},
+		{3734999, "3.73 MB"},
+		{2000000000, "2 GB"},
+		{5000000000000000, "5000 TB"},
+	}
+	for _, test := range tests {
+		if got := formatSize(test.size); got != test.want {
+			t.Errorf("formatSize(%d) = %q, want %q", test.size, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+// state.go // This file keeps track of what bigdl installed, in StateDir //>
+package bigdl
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/goccy/go-json"
+)
+
+// InstalledBinary records how a file in an install directory was installed by bigdl
+type InstalledBinary struct {
+	Name     string   `json:"name"`             // Name of the binary in the repos
+	Extras   []string `json:"extras,omitempty"` // Install paths of the companion binaries (extra_bins) that were installed along with it
+	Checksum          // Hashes of the file when it was installed, see Verify
+	// Archive holds the hashes of the archive the binary was extracted from, if it was. Update compares them to the repo's, since the binary itself has no published hash
+	Archive *Checksum `json:"archive,omitempty"`
+}
+
+// installedFile returns the path of the file that holds the InstalledBinary records, keyed by install path
+func (c *Client) installedFile() string {
+	return filepath.Join(c.opts.StateDir, "installed.json")
+}
+
+// loadInstalled reads the InstalledBinary records. A missing file is not an error.
+func (c *Client) loadInstalled() (map[string]InstalledBinary, error) {
+	installed := make(map[string]InstalledBinary)
+	data, err := os.ReadFile(c.installedFile())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return installed, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %v", c.installedFile(), err)
+	}
+	if err := json.Unmarshal(data, &installed); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %v", c.installedFile(), err)
+	}
+	return installed, nil
+}
+
+// saveInstalled writes the InstalledBinary records, replacing the file atomically.
+func (c *Client) saveInstalled(installed map[string]InstalledBinary) error {
+	if err := os.MkdirAll(c.opts.StateDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create state directory: %v", err)
+	}
+	data, err := json.MarshalIndent(installed, "", "  ")
+	if err != nil {
+		return err
+	}
+	tempFile := c.installedFile() + ".tmp"
+	if err := os.WriteFile(tempFile, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %v", tempFile, err)
+	}
+	return os.Rename(tempFile, c.installedFile())
+}
+
+// installedRecord returns the record of the binary at installPath, or nil if bigdl didn't install it. The history keeps it to restore it on Undo
+func (c *Client) installedRecord(installPath string) *InstalledBinary {
+	if record, ok := c.Installed(installPath); ok {
+		return &record
+	}
+	return nil
+}
+
+// Installed returns the record of the binary at installPath, if there's one.
+func (c *Client) Installed(installPath string) (InstalledBinary, bool) {
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
+
+	installed, err := c.loadInstalled()
+	if err != nil {
+		return InstalledBinary{}, false
+	}
+	record, ok := installed[absPath(installPath)]
+	return record, ok
+}
+
+// recordInstall stores the record of the binary at installPath, along with its current hashes. Nothing is stored if Options.RecordInstalls is false.
+func (c *Client) recordInstall(installPath string, record InstalledBinary) error {
+	if !c.opts.RecordInstalls {
+		return nil
+	}
+	record.SHA256 = fileSHA256(installPath)
+	record.B3SUM, _ = B3SUMFile(installPath)
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
+
+	installed, err := c.loadInstalled()
+	if err != nil {
+		return err
+	}
+	installed[absPath(installPath)] = record
+	return c.saveInstalled(installed)
+}
+
+// forgetInstall deletes the record of the binary at installPath.
+func (c *Client) forgetInstall(installPath string) error {
+	c.stateMutex.Lock()
+	defer c.stateMutex.Unlock()
+
+	installed, err := c.loadInstalled()
+	if err != nil {
+		return err
+	}
+	if _, ok := installed[absPath(installPath)]; !ok {
+		return nil
+	}
+	delete(installed, absPath(installPath))
+	return c.saveInstalled(installed)
+}
+
+// CatalogueName returns the name in the repos of the binary at installPath. It differs from its file name when it was installed under an alias ("src:dest").
+func (c *Client) CatalogueName(installPath string) string {
+	if record, ok := c.Installed(installPath); ok && record.Name != "" {
+		return record.Name
+	}
+	return filepath.Base(installPath)
+}
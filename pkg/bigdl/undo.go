@@ -0,0 +1,208 @@
+// undo.go // This file implements the backups of replaced and removed binaries, and undoing the last transaction with them //>
+package bigdl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// ErrNothingToUndo is returned by Undo when the history has no transaction left to undo
+var ErrNothingToUndo = errors.New("there's nothing to undo")
+
+// UndoResult describes the outcome of reverting a change of the history
+type UndoResult struct {
+	Change HistoryEntry // The change that was reverted
+	Action string       // What was done to revert it: "removed" or "restored"
+	Err    error        // Why it could not be reverted
+}
+
+// backupsDir returns the directory that holds the copies of the binaries replaced and removed by bigdl, named after their SHA256
+func (c *Client) backupsDir() string {
+	return filepath.Join(c.opts.StateDir, "backups")
+}
+
+// backup keeps a copy of the file at installPath, which is about to be replaced or removed, and returns its SHA256. Nothing is copied if Options.RecordInstalls is false or if there's no such file.
+func (c *Client) backup(installPath string) string {
+	sha256 := fileSHA256(installPath)
+	if sha256 == "" || !c.opts.RecordInstalls {
+		return sha256
+	}
+
+	backupPath := filepath.Join(c.backupsDir(), sha256)
+	if FileExists(backupPath) {
+		return sha256
+	}
+	if err := os.MkdirAll(c.backupsDir(), 0o755); err != nil {
+		c.logf("Warning: failed to create %s: %v\n", c.backupsDir(), err)
+		return sha256
+	}
+	if err := copyFileTo(installPath, backupPath); err != nil {
+		c.logf("Warning: failed to back up %s, its change can't be undone: %v\n", installPath, err)
+	}
+	return sha256
+}
+
+// copyFileTo copies the file at src to dst, leaving src untouched. dst is written to a temporary file first, so that it is never left half-written
+func copyFileTo(src, dst string) error {
+	sourceFile, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer sourceFile.Close()
+
+	tempFile, err := os.CreateTemp(filepath.Dir(dst), filepath.Base(dst)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tempFile.Name())
+
+	if _, err := io.Copy(tempFile, sourceFile); err != nil {
+		tempFile.Close()
+		return err
+	}
+	if err := tempFile.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tempFile.Name(), 0o755); err != nil {
+		return err
+	}
+	return os.Rename(tempFile.Name(), dst)
+}
+
+// undoableTransactions returns the transactions of the history that weren't undone yet, newest first, along with their successful changes
+func undoableTransactions(entries []HistoryEntry) ([]string, map[string][]HistoryEntry) {
+	reverted := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.Reverts != "" {
+			reverted[entry.Reverts] = true
+		}
+	}
+
+	var transactions []string
+	changes := make(map[string][]HistoryEntry)
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		if entry.Command == "undo" || entry.Result != "ok" || reverted[entry.Transaction] {
+			continue
+		}
+		if _, seen := changes[entry.Transaction]; !seen {
+			transactions = append(transactions, entry.Transaction)
+		}
+		changes[entry.Transaction] = append(changes[entry.Transaction], entry)
+	}
+	return transactions, changes
+}
+
+// pruneBackups deletes the backups that the last Options.UndoableTransactions transactions don't need
+func (c *Client) pruneBackups() {
+	// Without a history, there's no telling which backups are needed
+	if !c.opts.RecordInstalls {
+		return
+	}
+	entries, err := c.History("")
+	if err != nil {
+		return
+	}
+	transactions, changes := undoableTransactions(entries)
+	if len(transactions) > c.opts.UndoableTransactions {
+		transactions = transactions[:c.opts.UndoableTransactions]
+	}
+
+	needed := make(map[string]bool)
+	for _, transaction := range transactions {
+		for _, change := range changes[transaction] {
+			needed[change.OldSHA256] = true
+		}
+	}
+
+	backups, err := os.ReadDir(c.backupsDir())
+	if err != nil {
+		return
+	}
+	for _, backup := range backups {
+		if !needed[backup.Name()] {
+			os.Remove(filepath.Join(c.backupsDir(), backup.Name()))
+		}
+	}
+}
+
+// Undo reverts the last install, update or remove that wasn't undone yet: binaries that were installed are removed, and the ones that were replaced or removed are restored from their backups (or from CacheDir).
+// Changes that failed aren't reverted, nor are binaries that changed since. It returns ErrNothingToUndo if there's no transaction left to undo.
+func (c *Client) Undo(ctx context.Context) ([]UndoResult, error) {
+	entries, err := c.History("")
+	if err != nil {
+		return nil, err
+	}
+	transactions, changes := undoableTransactions(entries)
+	if len(transactions) == 0 {
+		return nil, ErrNothingToUndo
+	}
+	transaction := transactions[0]
+	undoTransaction := newTransaction()
+	defer c.pruneBackups()
+
+	var results []UndoResult
+	// Changes are reverted in the opposite order they were made in, changes holds them newest first
+	for _, change := range changes[transaction] {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+
+		result := UndoResult{Change: change}
+		oldSHA256, oldRecord := fileSHA256(change.Path), c.installedRecord(change.Path)
+		switch {
+		case oldSHA256 != change.NewSHA256:
+			result.Err = fmt.Errorf("'%s' changed since, it was left as it is", change.Path)
+		case change.OldSHA256 == "":
+			// It was installed, and there was nothing at its path before
+			result.Action = "removed"
+			if err := os.Remove(change.Path); err != nil {
+				result.Err = fmt.Errorf("failed to remove '%s': %v", change.Path, err)
+			} else if err := c.forgetInstall(change.Path); err != nil {
+				c.logf("Warning: %v\n", err)
+			}
+		default:
+			result.Action = "restored"
+			result.Err = c.restore(change)
+		}
+
+		// Every change is recorded, even the ones that couldn't be reverted, so that the next Undo moves on to the previous transaction
+		undoEntry := c.newChange(ctx, undoTransaction, "undo", change.Binary, change.Path, oldSHA256, oldRecord, result.Err)
+		undoEntry.Reverts = transaction
+		c.recordEntry(undoEntry)
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// restore puts back the file that the change replaced or removed, from its backup or from CacheDir, along with the record it had. Files bigdl didn't install are left without a record.
+func (c *Client) restore(change HistoryEntry) error {
+	source := filepath.Join(c.backupsDir(), change.OldSHA256)
+	if !FileExists(source) {
+		// The binaries cached by `run` may be the one that was removed
+		cachedFile := filepath.Join(c.opts.CacheDir, filepath.Base(change.Binary))
+		if fileSHA256(cachedFile) != change.OldSHA256 {
+			return fmt.Errorf("there's no backup of '%s' (%s)", change.Path, change.OldSHA256)
+		}
+		source = cachedFile
+	}
+
+	if err := os.MkdirAll(filepath.Dir(change.Path), 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %v", filepath.Dir(change.Path), err)
+	}
+	if err := copyFileTo(source, change.Path); err != nil {
+		return fmt.Errorf("failed to restore '%s': %v", change.Path, err)
+	}
+	if change.OldRecord == nil {
+		if err := c.forgetInstall(change.Path); err != nil {
+			c.logf("Warning: %v\n", err)
+		}
+	} else if err := c.recordInstall(change.Path, *change.OldRecord); err != nil {
+		c.logf("Warning: %v\n", err)
+	}
+	return nil
+}
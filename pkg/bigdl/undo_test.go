@@ -0,0 +1,31 @@
+package bigdl
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUndoableTransactions(t *testing.T) {
+	entries := []HistoryEntry{
+		{Transaction: "a", Command: "install", Binary: "btop", Result: "ok"},
+		{Transaction: "b", Command: "install", Binary: "pkg/main", Result: "ok"},
+		{Transaction: "b", Command: "install", Binary: "pkg/comp", Result: "ok"},
+		{Transaction: "c", Command: "remove", Binary: "btop", Result: "ok"},
+		{Transaction: "d", Command: "undo", Binary: "btop", Result: "ok", Reverts: "c"},
+		{Transaction: "e", Command: "update", Binary: "ls", Result: "failed to fetch"},
+		{Transaction: "f", Command: "update", Binary: "ls", Result: "ok"},
+	}
+
+	transactions, changes := undoableTransactions(entries)
+	// Newest first, without the undone, the undos and the failures
+	if want := []string{"f", "b", "a"}; !reflect.DeepEqual(transactions, want) {
+		t.Fatalf("transactions = %v, want %v", transactions, want)
+	}
+	// The changes of a transaction are reverted in reverse order
+	if got := changes["b"]; len(got) != 2 || got[0].Binary != "pkg/comp" || got[1].Binary != "pkg/main" {
+		t.Errorf("changes of b = %+v", got)
+	}
+	if _, found := changes["c"]; found {
+		t.Error("the undone transaction c should not be undoable")
+	}
+}
@@ -0,0 +1,191 @@
+// update.go // This file implements the parallel update of installed binaries whose hash differs from the repos, and the validation of installed programs //>
+package bigdl
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+)
+
+// UpdateStatus is the outcome of checking a binary for updates
+type UpdateStatus int
+
+const (
+	UpdateSkipped  UpdateStatus = iota // The binary could not be checked (missing, not in the metadata, no hash...)
+	UpdateUpToDate                     // The binary matches the repo's
+	UpdateUpdated                      // The repo's version differed and was installed
+	UpdateFailed                       // The repo's version differed but could not be installed
+)
+
+// UpdateResult describes the outcome of checking a binary in InstallDir for updates
+type UpdateResult struct {
+	Name    string // File name of the binary in InstallDir
+	Status  UpdateStatus
+	Message string // Describes the Status. e.g: "Skipping foo because the b3sum and SHA256 fields are null."
+	Err     error  // Set when Status is UpdateFailed
+	// HookErrors holds the failures of the pre-update and post-update hooks. They don't stop the update
+	HookErrors []error
+}
+
+// UpdateOptions configures Update
+type UpdateOptions struct {
+	// Jobs is the number of binaries processed at once. Defaults to 1
+	Jobs int
+	// Progress, if set, is called with the number of binaries checked so far, their total and the result of the last one. Calls are serialized
+	Progress func(checked, total int, result UpdateResult)
+}
+
+// Update checks the binaries in InstallDir for updates and installs any that have changed. If binaryNames is nil, every binary in InstallDir that is in the repos is checked.
+// If ctx is cancelled, the results of the binaries checked so far are returned along with ctx.Err().
+// Updates never use the cache, don't install extras and don't show the progressbar.
+func (c *Client) Update(ctx context.Context, binaryNames []string, opts UpdateOptions) ([]UpdateResult, error) {
+	programsToUpdate, err := c.ValidatePrograms(ctx, binaryNames)
+	if err != nil {
+		return nil, fmt.Errorf("error validating programs: %v", err)
+	}
+
+	// Load the metadata once, instead of once per program
+	catalogue, err := c.Catalogue(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching metadata: %v", err)
+	}
+
+	jobs := opts.Jobs
+	if jobs < 1 {
+		jobs = 1
+	}
+
+	transaction := newTransaction()
+	defer c.pruneBackups()
+	var (
+		results       []UpdateResult
+		progressMutex sync.Mutex
+	)
+	// report stores the result and forwards it to opts.Progress
+	report := func(result UpdateResult) {
+		progressMutex.Lock()
+		defer progressMutex.Unlock()
+		results = append(results, result)
+		if opts.Progress != nil {
+			opts.Progress(len(results), len(programsToUpdate), result)
+		}
+	}
+
+	updateProgram := func(program string) UpdateResult {
+		if ctx.Err() != nil {
+			return UpdateResult{Name: program, Status: UpdateSkipped, Message: fmt.Sprintf("Interrupted, %s was not checked.", program)}
+		}
+		installPath := filepath.Join(c.opts.InstallDir, program)
+		if !FileExists(installPath) {
+			return UpdateResult{Name: program, Status: UpdateSkipped, Message: fmt.Sprintf("Warning: Tried to update a non-existent program %s. Skipping.", program)}
+		}
+		// Binaries installed under an alias are looked up by their name in the repos
+		binaryName := c.CatalogueName(installPath)
+		binaryInfo, found := catalogue.Lookup(binaryName)
+		if !found {
+			return UpdateResult{Name: program, Status: UpdateSkipped, Message: fmt.Sprintf("Warning: Failed to get metadata for %s. Skipping.", program)}
+		}
+
+		// Skip if both hash fields are null
+		if binaryInfo.Checksum().Empty() {
+			return UpdateResult{Name: program, Status: UpdateSkipped, Message: fmt.Sprintf("Skipping %s because the b3sum and SHA256 fields are null.", program)}
+		}
+
+		mode, target := installMode{}, binaryName+":"+program
+		var upToDate bool
+		if archiveExtension(binaryName) != "" {
+			// Binaries extracted from an archive are compared by the archive they came from, and only their entry is extracted again
+			record, _ := c.Installed(installPath)
+			upToDate = record.Archive != nil && record.Archive.Equal(binaryInfo.Checksum())
+			mode.entries, target = []string{program}, binaryName
+		} else {
+			// The b3sum is preferred, as BLAKE3 is much faster than SHA256 to compute
+			local := &fileChecksum{path: installPath}
+			matches, err := local.matches(binaryInfo.Checksum())
+			if err != nil {
+				return UpdateResult{Name: program, Status: UpdateSkipped, Message: fmt.Sprintf("Warning: Failed to hash %s. Skipping.", program)}
+			}
+			upToDate = matches
+		}
+		if upToDate {
+			return UpdateResult{Name: program, Status: UpdateUpToDate, Message: fmt.Sprintf("No updates available for %s.", program)}
+		}
+		// The history refers to files by their SHA256
+		localSHA256, oldRecord := fileSHA256(installPath), c.installedRecord(installPath)
+
+		hookErrors := c.runHooks(ctx, PreUpdate, c.installedTarget(ctx, binaryName, installPath))
+		_, err := c.install(ctx, []string{target}, mode)
+		c.recordChange(ctx, transaction, "update", binaryName, installPath, localSHA256, oldRecord, err)
+		if err != nil {
+			return UpdateResult{Name: program, Status: UpdateFailed, Message: fmt.Sprintf("Failed to update %s.", program), Err: err, HookErrors: hookErrors}
+		}
+		hookErrors = append(hookErrors, c.runHooks(ctx, PostUpdate, c.installedTarget(ctx, binaryName, installPath))...)
+		return UpdateResult{Name: program, Status: UpdateUpdated, Message: fmt.Sprintf("Successfully updated %s.", program), HookErrors: hookErrors}
+	}
+
+	// Feed the programs to a bounded pool of workers
+	programs := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < jobs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for program := range programs {
+				report(updateProgram(program))
+			}
+		}()
+	}
+feed:
+	for _, program := range programsToUpdate {
+		// Once cancelled, the remaining programs are not checked. The downloads in flight fail, and leave the installed binaries untouched
+		select {
+		case programs <- program:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(programs)
+
+	// Wait for all workers to finish
+	wg.Wait()
+
+	return results, ctx.Err()
+}
+
+// ValidatePrograms returns the binaries in InstallDir that are in the repos. If binaryNames is nil, every file in InstallDir is considered.
+func (c *Client) ValidatePrograms(ctx context.Context, binaryNames []string) ([]string, error) {
+	// Fetch the list of binaries from the remote source once
+	remotePrograms, err := c.ListNames(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remote binaries: %w", err)
+	}
+
+	validPrograms := make([]string, 0)
+	isValid := func(installPath string) bool {
+		_, name := c.SplitRepoName(c.CatalogueName(installPath))
+		return contains(remotePrograms, name)
+	}
+
+	// If binaryNames is nil, validate all programs in the install directory
+	if binaryNames == nil {
+		files, err := listFilesInDir(c.opts.InstallDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list files in %s: %w", c.opts.InstallDir, err)
+		}
+		for _, file := range files {
+			if isValid(file) {
+				validPrograms = append(validPrograms, filepath.Base(file))
+			}
+		}
+		return validPrograms, nil
+	}
+
+	// Only check the ones specified in binaryNames
+	for _, program := range removeDuplicates(binaryNames) {
+		if isValid(filepath.Join(c.opts.InstallDir, program)) {
+			validPrograms = append(validPrograms, program)
+		}
+	}
+	return validPrograms, nil
+}
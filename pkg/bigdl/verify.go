@@ -0,0 +1,127 @@
+// verify.go // This file implements the integrity checks of installed binaries //>
+package bigdl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// VerifyStatus is the outcome of checking an installed binary with Verify
+type VerifyStatus int
+
+const (
+	VerifyOK            VerifyStatus = iota // The binary is the one that was installed, and the repos publish the same one
+	VerifyOutdated                          // The binary is the one that was installed, but the repos publish another one now
+	VerifyModified                          // The binary changed since it was installed
+	VerifyUnknownOrigin                     // The binary wasn't installed by bigdl, and the repos don't publish it
+)
+
+// String returns the name of the status, as shown by `bigdl verify`
+func (s VerifyStatus) String() string {
+	switch s {
+	case VerifyOK:
+		return "ok"
+	case VerifyOutdated:
+		return "outdated"
+	case VerifyModified:
+		return "modified"
+	default:
+		return "unknown origin"
+	}
+}
+
+// VerifyResult describes the outcome of checking an installed binary with Verify
+type VerifyResult struct {
+	Name      string // File name of the binary in InstallDir
+	Path      string
+	Status    VerifyStatus
+	Message   string   // Details the Status. e.g: "matches its install-time hash, the repos don't publish one"
+	Local     Checksum // Hashes of the binary, only those that were needed are computed
+	Recorded  Checksum // Hashes of the binary when bigdl installed it, if it has a record
+	Catalogue Checksum // Hashes the repos publish for it, if any
+	Err       error    // Why the binary couldn't be checked
+}
+
+// Verify hashes the binaries of InstallDir and compares them to the hash recorded when they were installed and to the one the repos publish. If binaryNames is nil, every file in InstallDir is checked.
+func (c *Client) Verify(ctx context.Context, binaryNames []string) ([]VerifyResult, error) {
+	catalogue, err := c.Catalogue(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching metadata: %v", err)
+	}
+
+	if binaryNames == nil {
+		files, err := listFilesInDir(c.opts.InstallDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list files in %s: %w", c.opts.InstallDir, err)
+		}
+		for _, file := range files {
+			binaryNames = append(binaryNames, filepath.Base(file))
+		}
+	}
+
+	var results []VerifyResult
+	for _, binaryName := range removeDuplicates(binaryNames) {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		results = append(results, c.verifyBinary(catalogue, binaryName))
+	}
+	return results, nil
+}
+
+// verifyBinary checks the binary of InstallDir named binaryName
+func (c *Client) verifyBinary(catalogue *Catalogue, binaryName string) (result VerifyResult) {
+	installPath := filepath.Join(c.opts.InstallDir, filepath.Base(binaryName))
+	result = VerifyResult{Name: filepath.Base(binaryName), Path: installPath}
+	if _, err := os.Stat(installPath); err != nil {
+		result.Err = err
+		return result
+	}
+
+	record, recorded := c.Installed(installPath)
+	result.Recorded = record.Checksum
+	if binaryInfo, found := catalogue.Lookup(c.CatalogueName(installPath)); found {
+		result.Catalogue = binaryInfo.Checksum()
+	}
+
+	// Each hash is compared with the same algorithm, BLAKE3 whenever possible
+	local := &fileChecksum{path: installPath}
+	defer func() { result.Local = local.sum }()
+	matchesRecord, err := local.matches(result.Recorded)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	matchesCatalogue, err := local.matches(result.Catalogue)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	// The repos publish the hash of the archive a binary was extracted from, not the binary's
+	if archiveExtension(record.Name) != "" {
+		matchesCatalogue = record.Archive != nil && record.Archive.Equal(result.Catalogue)
+	}
+
+	switch {
+	case !result.Recorded.Empty() && !matchesRecord:
+		result.Status, result.Message = VerifyModified, "it changed since it was installed"
+	case matchesCatalogue:
+		result.Status, result.Message = VerifyOK, "it matches the repo's"
+	case !result.Recorded.Empty() && !result.Catalogue.Empty():
+		result.Status, result.Message = VerifyOutdated, "it is the one that was installed, the repo's differs"
+	case !result.Recorded.Empty():
+		result.Status, result.Message = VerifyOK, "it matches its install-time hash, the repos don't publish one"
+	case recorded && !result.Catalogue.Empty():
+		// Records older than the install-time hashes can't tell a modification from an update
+		result.Status, result.Message = VerifyOutdated, "it doesn't match the repo's, and its install-time hash wasn't recorded"
+	case recorded:
+		result.Status, result.Message = VerifyOK, "it was installed by bigdl, but neither its install-time hash nor the repo's is known"
+	case !result.Catalogue.Empty():
+		result.Status, result.Message = VerifyUnknownOrigin, "it wasn't installed by bigdl, and doesn't match the repo's"
+	default:
+		result.Status, result.Message = VerifyUnknownOrigin, "it wasn't installed by bigdl, and the repos don't publish a hash for it"
+	}
+	return result
+}
@@ -2,25 +2,23 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 )
 
-func remove(binariesToRemove []string) {
-	for _, binaryName := range binariesToRemove {
-		// Use the base name of binaryName for constructing the cachedFile path
-		baseName := filepath.Base(binaryName)
-		installPath := filepath.Join(InstallDir, baseName)
-		err := os.Remove(installPath)
-		if err != nil {
-			if os.IsNotExist(err) {
-				fmt.Fprintf(os.Stderr, "Warning: '%s' does not exist in %s\n", baseName, InstallDir)
-			} else {
-				fmt.Fprintf(os.Stderr, "Error: Failed to remove '%s' from %s. %v\n", baseName, InstallDir, err)
-			}
-			continue
+func remove(ctx context.Context, binariesToRemove []string) {
+	for _, result := range newClient().Remove(ctx, binariesToRemove) {
+		printHookErrors(result.HookErrors)
+		switch {
+		case errors.Is(result.Err, os.ErrNotExist):
+			fmt.Fprintf(os.Stderr, "Warning: '%s' does not exist in %s\n", result.Name, filepath.Dir(result.Path))
+		case result.Err != nil:
+			fmt.Fprintf(os.Stderr, "Error: %v\n", result.Err)
+		default:
+			fmt.Printf("'%s' removed from %s\n", result.Name, filepath.Dir(result.Path))
 		}
-		fmt.Printf("'%s' removed from %s\n", baseName, InstallDir)
 	}
 }
@@ -2,15 +2,19 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"sort"
 	"strings"
 	"syscall"
 	"time"
+
+	"github.com/xplshn/bigdl/pkg/bigdl"
 )
 
 var (
@@ -18,18 +22,8 @@ var (
 	silentMode  bool
 )
 
-// ReturnCachedFile retrieves the cached file location. Returns an empty string and error code 1 if not found.
-func ReturnCachedFile(binaryName string) (string, int) {
-	cachedBinary := filepath.Join(TEMPDIR, binaryName)
-	if fileExists(cachedBinary) {
-		return cachedBinary, 0
-	}
-	// The file does not exist, return an error
-	return "", 1
-}
-
 // RunFromCache runs the binary from cache or fetches it if not found.
-func RunFromCache(binaryName string, args []string) {
+func RunFromCache(ctx context.Context, binaryName string, args []string) {
 	// purifyVars is a function to purify binaryName and args.
 	purifyVars := func() {
 		if len(args) > 0 {
@@ -44,6 +38,8 @@ func RunFromCache(binaryName string, args []string) {
 	verbose := flag.Bool("verbose", false, "Enable verbose mode")
 	silent := flag.Bool("silent", false, "Enable silent mode")
 	transparent := flag.Bool("transparent", false, "Enable transparent mode")
+	ephemeral := flag.Bool("ephemeral", false, "Run from a private temporary directory, leaving the cache untouched")
+	memfd := flag.Bool("memfd", false, "Run from memory, without writing the binary to disk")
 
 	flagsAndBinaryName := append(strings.Fields(binaryName), args...)
 	flag.CommandLine.Parse(flagsAndBinaryName)
@@ -59,7 +55,7 @@ func RunFromCache(binaryName string, args []string) {
 
 	if *silent {
 		silentMode = true
-		UseProgressBar = false
+		options.ProgressBar = false
 		purifyVars()
 	}
 
@@ -75,26 +71,48 @@ func RunFromCache(binaryName string, args []string) {
 		}
 	}
 
+	if *ephemeral {
+		purifyVars()
+	}
+
+	if *memfd {
+		purifyVars()
+	}
+
 	if binaryName == "" {
 		errorOut("error: Binary name not provided\n")
 	}
 
+	if *memfd || os.Getenv("BIGDL_MEMFD") == "1" {
+		RunFromMemfd(ctx, binaryName, args)
+	}
+
+	if *ephemeral {
+		RunEphemeral(ctx, binaryName, args)
+	}
+
 	// Use the base name of binaryName to construc the cachedFile path. This way requests like toybox/wget are supported
-	cachedFile := filepath.Join(TEMPDIR, filepath.Base(binaryName))
+	cachedFile := filepath.Join(options.CacheDir, filepath.Base(binaryName))
 
-	if fileExists(cachedFile) && isExecutable(cachedFile) {
+	if bigdl.FileExists(cachedFile) && bigdl.IsExecutable(cachedFile) {
 		if !silentMode {
 			fmt.Printf("Running '%s' from cache...\n", binaryName)
 		}
-		runBinary(cachedFile, args, verboseMode)
+		// Refresh its atime before cleaning, runBinary does not return, so the cache has to be cleaned beforehand
+		if info, err := os.Stat(cachedFile); err == nil {
+			_ = os.Chtimes(cachedFile, time.Now(), info.ModTime())
+		}
 		cleanCache()
+		runBinary(cachedFile, args, verboseMode)
 	} else {
 		if verboseMode {
 			fmt.Printf("Couldn't find '%s' in the cache. Fetching a new one...\n", binaryName)
 		}
-		InstallDir = TEMPDIR
+		options.InstallDir = options.CacheDir
+		options.InstallExtras = false
+		options.RecordInstalls = false
 		InstallMessage = ""
-		if err := installCommand(silentMode, binaryName); err != nil {
+		if err := installCommand(ctx, silentMode, []string{binaryName}); err != nil {
 			errorOut("%v\n", err)
 		}
 		cleanCache()
@@ -102,28 +120,127 @@ func RunFromCache(binaryName string, args []string) {
 	}
 }
 
-// runBinary executes the binary with the given arguments.
+// RunEphemeral downloads the binary to a private temporary directory, verifies it, runs it and removes it afterwards. The cache is never read nor written.
+func RunEphemeral(ctx context.Context, binaryName string, args []string) {
+	ephemeralDir, err := os.MkdirTemp("", "bigdl_ephemeral_")
+	if err != nil {
+		errorOut("error: Failed to create a temporary directory: %v\n", err)
+	}
+
+	// Point the downloader at the private directory, so that not even the .tmp file lands in the cache
+	options.CacheDir = ephemeralDir
+	client := newClient()
+	binaryPath := filepath.Join(ephemeralDir, filepath.Base(binaryName))
+
+	if verboseMode {
+		fmt.Printf("Fetching '%s' to %s...\n", binaryName, ephemeralDir)
+	}
+
+	url, err := client.FindURL(ctx, binaryName)
+	if err != nil {
+		os.RemoveAll(ephemeralDir)
+		errorOut("%v\n", err)
+	}
+	warnings, err := client.Download(ctx, url, binaryPath)
+	if err != nil {
+		os.RemoveAll(ephemeralDir)
+		errorOut("%v\n", err)
+	}
+	fmt.Print("\033[2K\r") // Clean the line
+	printWarnings(warnings)
+	if err := verifyBinary(ctx, client, binaryName, binaryPath); err != nil {
+		os.RemoveAll(ephemeralDir)
+		errorOut("%v\n", err)
+	}
+
+	exitCode := executeBinary(binaryPath, args, verboseMode)
+	if err := os.RemoveAll(ephemeralDir); err != nil && !silentMode {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to remove %s: %v\n", ephemeralDir, err)
+	}
+	os.Exit(exitCode)
+}
+
+// verifyBinary compares the hash of the file at binaryPath (b3sum or SHA256) against the one in the metadata. Binaries without a published hash are accepted with a warning.
+func verifyBinary(ctx context.Context, client *bigdl.Client, binaryName, binaryPath string) error {
+	verified, err := client.VerifyFile(ctx, binaryName, binaryPath)
+	if err != nil {
+		return err
+	}
+	if !verified && !silentMode {
+		fmt.Fprintf(os.Stderr, "Warning: No b3sum nor SHA256 available for '%s', it could not be verified\n", binaryName)
+	}
+	return nil
+}
+
+// printWarnings prints the warnings of bigdl.Client.CheckBinary (e.g: the binary is dynamically linked), unless the progressbar is disabled
+func printWarnings(warnings []string) {
+	if options.ProgressBar {
+		for _, warning := range warnings {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+		}
+	}
+}
+
+// runBinary replaces bigdl with the binary via execve(2), so that it keeps bigdl's PID, signals and terminal (job control works as expected).
+// In verbose mode (the exit code has to be reported) or when the exec fails, the binary is run as a child process instead.
 func runBinary(binaryPath string, args []string, verboseMode bool) {
+	if !verboseMode {
+		err := syscall.Exec(binaryPath, append([]string{binaryPath}, args...), os.Environ())
+		// syscall.Exec only returns if it failed
+		if !silentMode {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to exec '%s': %v. Running it as a child process instead\n", binaryPath, err)
+		}
+	}
+	os.Exit(executeBinary(binaryPath, args, verboseMode))
+}
+
+// forwardedSignals are relayed from bigdl to the child started by executeBinary.
+var forwardedSignals = []os.Signal{syscall.SIGTERM, syscall.SIGHUP, syscall.SIGUSR1, syscall.SIGUSR2}
+
+// executeBinary executes the binary as a child process with the given arguments and returns its exit code.
+func executeBinary(binaryPath string, args []string, verboseMode bool) int {
 	// Set the Controls for the Heart of the Sun
 	cmd := exec.Command(binaryPath, args...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	cmd.Stdin = os.Stdin
 
-	err := cmd.Run()
+	if err := cmd.Start(); err != nil {
+		fmt.Fprintf(os.Stderr, "error: Failed to run %s: %v\n", binaryPath, err)
+		return 127
+	}
+
+	// The child shares our process group, so the terminal already delivers SIGINT, SIGQUIT and SIGTSTP to it. Catch them so that bigdl outlives the child, and relay the rest
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, append(forwardedSignals, os.Interrupt, syscall.SIGQUIT)...)
+	go func() {
+		for sig := range sigChan {
+			if sig != os.Interrupt && sig != syscall.SIGQUIT {
+				_ = cmd.Process.Signal(sig)
+			}
+		}
+	}()
+
+	err := cmd.Wait()
+	signal.Stop(sigChan)
+	close(sigChan)
+
 	exitCode := cmd.ProcessState.ExitCode()
+	if status, ok := cmd.ProcessState.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		exitCode = 128 + int(status.Signal())
+	}
 
 	if err != nil && verboseMode {
 		fmt.Printf("The program (%s) errored out with a non-zero exit code (%d).\n", binaryPath, exitCode)
 	}
 
-	os.Exit(exitCode)
+	return exitCode
 }
 
 // cleanCache removes the oldest binaries when the cache size exceeds MaxCacheSize.
 func cleanCache() {
 	// Get a list of all binaries in the cache directory
-	files, err := os.ReadDir(TEMPDIR)
+	files, err := os.ReadDir(options.CacheDir)
 	if err != nil {
 		fmt.Printf("Error reading cache directory: %v\n", err)
 		return
@@ -151,7 +268,7 @@ func cleanCache() {
 
 		// Use syscall to get atime
 		var stat syscall.Stat_t
-		err = syscall.Stat(filepath.Join(TEMPDIR, entry.Name()), &stat)
+		err = syscall.Stat(filepath.Join(options.CacheDir, entry.Name()), &stat)
 		if err != nil {
 			fmt.Printf("Error getting file stat: %v\n", err)
 			continue
@@ -168,7 +285,7 @@ func cleanCache() {
 
 	// Delete the oldest binaries
 	for i := 0; i < BinariesToDelete; i++ {
-		err := os.Remove(filepath.Join(TEMPDIR, filesWithAtime[i].info.Name()))
+		err := os.Remove(filepath.Join(options.CacheDir, filesWithAtime[i].info.Name()))
 		if err != nil {
 			if !silentMode { // Check if not in silent mode before printing
 				fmt.Printf("Error removing file: %v\n", err)
@@ -0,0 +1,163 @@
+// selfupdate.go // This file implements the "self-update" functionality, which upgrades bigdl itself //>
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/xplshn/bigdl/pkg/bigdl"
+)
+
+// SelfUpdateURL is the endpoint that describes the latest release of bigdl. It must answer in the format of GitHub's releases API. It takes the value of $BIGDL_SELFUPDATE_URL if it is set
+var SelfUpdateURL = "https://api.github.com/repos/xplshn/bigdl/releases/latest"
+
+// releaseInfo holds the fields of a GitHub release that self-update needs
+type releaseInfo struct {
+	TagName string `json:"tag_name"`
+	Assets  []struct {
+		Name string `json:"name"`
+		URL  string `json:"browser_download_url"`
+	} `json:"assets"`
+}
+
+// assetURL returns the download URL of the asset with the given name, or an empty string.
+func (r releaseInfo) assetURL(name string) string {
+	for _, asset := range r.Assets {
+		if asset.Name == name {
+			return asset.URL
+		}
+	}
+	return ""
+}
+
+// selfUpdate checks SelfUpdateURL for a newer bigdl, verifies its SHA256 and atomically replaces the running executable with it. If checkOnly is set, it only reports whether an update is available.
+func selfUpdate(ctx context.Context, checkOnly bool) error {
+	if url := os.Getenv("BIGDL_SELFUPDATE_URL"); url != "" {
+		SelfUpdateURL = url
+	}
+
+	client := newClient()
+	var release releaseInfo
+	if err := client.FetchJSON(ctx, SelfUpdateURL, &release); err != nil {
+		return err
+	}
+	// An answer that isn't a release (e.g: "API rate limit exceeded") must not read as being up-to-date
+	if release.TagName == "" {
+		return fmt.Errorf("error: %s did not describe a release", SelfUpdateURL)
+	}
+
+	if compareVersions(release.TagName, VERSION) <= 0 {
+		fmt.Printf("bigdl %s is up-to-date\n", VERSION)
+		return nil
+	}
+	if checkOnly {
+		fmt.Printf("A new version of bigdl is available: %s (current: %s)\n", release.TagName, VERSION)
+		return nil
+	}
+
+	assetName := "bigdl_" + runtime.GOARCH
+	binaryURL := release.assetURL(assetName)
+	if binaryURL == "" {
+		return fmt.Errorf("error: release %s does not provide '%s'", release.TagName, assetName)
+	}
+
+	expectedSHA256, err := releaseChecksum(ctx, client, release, assetName)
+	if err != nil {
+		return err
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("error: could not determine the path of bigdl: %v", err)
+	}
+	if executable, err = filepath.EvalSymlinks(executable); err != nil {
+		return fmt.Errorf("error: could not resolve the path of bigdl: %v", err)
+	}
+
+	// Download next to the executable, so that the final rename does not cross filesystems and is atomic
+	newExecutable := filepath.Join(filepath.Dir(executable), fmt.Sprintf(".%s.new-%d", filepath.Base(executable), os.Getpid()))
+	warnings, err := client.Download(ctx, binaryURL, newExecutable)
+	fmt.Print("\033[2K\r") // Clean the line
+	if err != nil {
+		return err
+	}
+
+	printWarnings(warnings)
+
+	localSHA256, err := bigdl.SHA256File(newExecutable)
+	if err != nil {
+		os.Remove(newExecutable)
+		return err
+	}
+	if localSHA256 != expectedSHA256 {
+		os.Remove(newExecutable)
+		return fmt.Errorf("error: SHA256 mismatch for %s. Expected %s, got %s", assetName, expectedSHA256, localSHA256)
+	}
+
+	if err := os.Rename(newExecutable, executable); err != nil {
+		os.Remove(newExecutable)
+		return fmt.Errorf("error: failed to replace %s: %v", executable, err)
+	}
+
+	fmt.Printf("bigdl was updated from %s to %s\n", VERSION, release.TagName)
+	return nil
+}
+
+// releaseChecksum finds the SHA256 of assetName in the release, either from "<asset>.sha256" or from a checksums file listing every asset.
+func releaseChecksum(ctx context.Context, client *bigdl.Client, release releaseInfo, assetName string) (string, error) {
+	candidates := []string{assetName + ".sha256", "checksums.txt", "SHA256SUMS"}
+	for _, candidate := range candidates {
+		url := release.assetURL(candidate)
+		if url == "" {
+			continue
+		}
+
+		var body strings.Builder
+		err := client.DownloadTo(ctx, url, &body)
+		fmt.Print("\033[2K\r") // Clean the line
+		if err != nil {
+			return "", err
+		}
+
+		// Lines follow the sha256sum(1) format: "<checksum>  <file>". A lone checksum is accepted too
+		scanner := bufio.NewScanner(strings.NewReader(body.String()))
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) == 1 && candidate == assetName+".sha256" {
+				return fields[0], nil
+			}
+			if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == assetName {
+				return fields[0], nil
+			}
+		}
+	}
+	return "", fmt.Errorf("error: release %s does not publish a checksum for '%s'", release.TagName, assetName)
+}
+
+// compareVersions compares two dotted version strings ("v1.6.9", "1.7"). It returns 1 if a is newer, -1 if b is newer and 0 if they are equal.
+func compareVersions(a, b string) int {
+	partsA := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	partsB := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(partsA) || i < len(partsB); i++ {
+		var numA, numB int
+		if i < len(partsA) {
+			numA, _ = strconv.Atoi(partsA[i])
+		}
+		if i < len(partsB) {
+			numB, _ = strconv.Atoi(partsB[i])
+		}
+		if numA != numB {
+			if numA > numB {
+				return 1
+			}
+			return -1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,23 @@
+package main
+
+import "testing"
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"v1.6.9", "1.6.9", 0},
+		{"1.7", "1.6.9", 1},
+		{"v1.6.10", "v1.6.9", 1},
+		{"1.6", "1.6.0", 0},
+		{"1.6.0.1", "1.6", 1},
+		{"v2", "v10", -1},
+		{"", "1.6.9", -1},
+	}
+	for _, test := range tests {
+		if got := compareVersions(test.a, test.b); got != test.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", test.a, test.b, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+// serve.go // This file implements the "serve" command //>
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/xplshn/bigdl/pkg/bigdl"
+)
+
+// serve serves the mirror at dir over HTTP on addr, until interrupted
+func serve(ctx context.Context, dir, addr string) {
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		errorOut("error: %s is not a directory\n", dir)
+	}
+
+	server := &http.Server{Addr: addr, Handler: bigdl.NewMirrorServer(dir)}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	// Other clients need an address they can reach, which a wildcard listen address doesn't tell
+	baseURL := "http://" + addr
+	if host, port, err := net.SplitHostPort(addr); err == nil && (host == "" || host == "0.0.0.0" || host == "::") {
+		baseURL = "http://<this machine's address>:" + port
+	}
+	fmt.Printf("Serving %s on %s\n", dir, addr)
+	fmt.Printf("Other clients can use it with BIGDL_MIRROR=%s\n", baseURL)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		errorOut("error: %v\n", err)
+	}
+}
@@ -0,0 +1,36 @@
+// undo.go // This file implements the "undo" command //>
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/xplshn/bigdl/pkg/bigdl"
+)
+
+// undo reverts the last transaction of the history, reporting what could not be reverted. It exits with a non-zero code if anything failed.
+func undo(ctx context.Context) {
+	results, err := newClient().Undo(ctx)
+	if errors.Is(err, bigdl.ErrNothingToUndo) {
+		fmt.Println("There's nothing to undo.")
+		return
+	}
+
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "Could not revert the %s of '%s': %v\n", result.Change.Command, result.Change.Binary, result.Err)
+			continue
+		}
+		fmt.Printf("Reverted the %s of '%s': %s %s\n", result.Change.Command, result.Change.Binary, result.Action, result.Change.Path)
+	}
+	if err != nil {
+		errorOut("%v\n", err)
+	}
+	if failed > 0 {
+		errorOut("%d change(s) could not be reverted\n", failed)
+	}
+}
@@ -2,159 +2,63 @@
 package main
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
+	"context"
 	"fmt"
-	"io"
-	"os"
-	"path/filepath"
-	"sync"
-	"sync/atomic"
+
+	"github.com/xplshn/bigdl/pkg/bigdl"
 )
 
-// update checks for updates to the valid programs and installs any that have changed.
-func update(programsToUpdate []string) error {
-	// 'Configure' external functions
-	UseProgressBar = false
-	InstallUseCache = false
+// update checks for updates to the valid programs and installs any that have changed. At most `jobs` programs are processed at once.
+func update(ctx context.Context, programsToUpdate []string, jobs int) error {
+	// Updates run in parallel, the messages of the client would clobber the progress
+	options.Logf = nil
 
-	// Initialize counters
 	var (
-		skipped, updated, errors, toBeChecked uint32
-		checked                               uint32
-		errorMessages                         string
-		padding                               = " "
+		skipped, updated, errors, checked int
+		errorMessages                     []string
+		hookErrors                        []error
+		padding                           = " "
 	)
 
-	// Call validateProgramsFrom with InstallDir and programsToUpdate
-	programsToUpdate, err := validateProgramsFrom(InstallDir, programsToUpdate)
-	if err != nil {
-		fmt.Println("Error validating programs:", err)
-		return err
+	// Print the status of each program as it is checked. The client serializes the calls
+	progress := func(done, total int, result bigdl.UpdateResult) {
+		checked = done
+		hookErrors = append(hookErrors, result.HookErrors...)
+		switch result.Status {
+		case bigdl.UpdateSkipped:
+			skipped++
+		case bigdl.UpdateUpdated:
+			updated++
+		case bigdl.UpdateFailed:
+			errors++
+			errorMessages = append(errorMessages, sanitizeString(fmt.Sprintf("Failed to update '%s', please check this file's properties, etc", result.Name)))
+		}
+		truncatePrintf("\033[2K\r<%d/%d> %s | %s", done, total, padding, result.Message)
 	}
 
-	// Calculate toBeChecked
-	toBeChecked = uint32(len(programsToUpdate))
-
-	// Use a mutex for thread-safe updates to the progress
-	var progressMutex sync.Mutex
-
-	// Use a wait group to wait for all programs to finish updating
-	var wg sync.WaitGroup
-
-	// Iterate over programsToUpdate and download/update each one concurrently
-	for _, program := range programsToUpdate {
-		// Increment the WaitGroup counter
-		wg.Add(1)
-
-		// Launch a goroutine to update the program
-		go func(program string) {
-			defer wg.Done()
-
-			installPath := filepath.Join(InstallDir, program)
-			if !fileExists(installPath) {
-				progressMutex.Lock()
-				atomic.AddUint32(&checked, 1)
-				atomic.AddUint32(&skipped, 1)
-				truncatePrintf("\033[2K\r<%d/%d> %s | Warning: Tried to update a non-existent program %s. Skipping.", atomic.LoadUint32(&checked), toBeChecked, padding, program)
-				progressMutex.Unlock()
-				return
-			}
-			localSHA256, err := getLocalSHA256(installPath)
-			if err != nil {
-				progressMutex.Lock()
-				atomic.AddUint32(&checked, 1)
-				atomic.AddUint32(&skipped, 1)
-				truncatePrintf("\033[2K\r<%d/%d> %s | Warning: Failed to get SHA256 for %s. Skipping.", atomic.LoadUint32(&checked), toBeChecked, padding, program)
-				progressMutex.Unlock()
-				return
-			}
-
-			binaryInfo, err := getBinaryInfo(program)
-			if err != nil {
-				progressMutex.Lock()
-				atomic.AddUint32(&checked, 1)
-				atomic.AddUint32(&skipped, 1)
-				truncatePrintf("\033[2K\r<%d/%d> %s | Warning: Failed to get metadata for %s. Skipping.", atomic.LoadUint32(&checked), toBeChecked, padding, program)
-				progressMutex.Unlock()
-				return
-			}
-
-			// Skip if the SHA field is null
-			if binaryInfo.SHA256 == "" {
-				progressMutex.Lock()
-				atomic.AddUint32(&checked, 1)
-				atomic.AddUint32(&skipped, 1)
-				truncatePrintf("\033[2K\r<%d/%d> %s | Skipping %s because the SHA256 field is null.", atomic.LoadUint32(&checked), toBeChecked, padding, program)
-				progressMutex.Unlock()
-				return
-			}
-
-			// Start update process
-			truncatePrintf("\033[2K\r<%d/%d> %s | Looking for differences in %s against the repo's...", atomic.LoadUint32(&checked), toBeChecked, padding, program)
-			if checkDifferences(localSHA256, binaryInfo.SHA256) == 1 {
-				truncatePrintf("\033[2K\r<%d/%d> %s | The repo's version of %s differs from yours. Updating...", atomic.LoadUint32(&checked), toBeChecked, padding, program)
-				err := installCommand(true, program)
-				if err != nil {
-					progressMutex.Lock()
-					atomic.AddUint32(&errors, 1)
-					errorMessages += sanitizeString(fmt.Sprintf("Failed to update '%s', please check this file's properties, etc\n", program))
-					progressMutex.Unlock()
-					return
-				}
-				progressMutex.Lock()
-				atomic.AddUint32(&checked, 1)
-				atomic.AddUint32(&updated, 1)
-				truncatePrintf("\033[2K\r<%d/%d> %s | Successfully updated %s.", atomic.LoadUint32(&checked), toBeChecked, padding, program)
-				progressMutex.Unlock()
-			} else {
-				progressMutex.Lock()
-				atomic.AddUint32(&checked, 1)
-				truncatePrintf("\033[2K\r<%d/%d> %s | No updates available for %s.", atomic.LoadUint32(&checked), toBeChecked, padding, program)
-				progressMutex.Unlock()
-			}
-		}(program)
+	results, err := newClient().Update(ctx, programsToUpdate, bigdl.UpdateOptions{Jobs: jobs, Progress: progress})
+	if err != nil && results == nil {
+		fmt.Println(err)
+		return err
 	}
 
-	// Wait for all goroutines to finish
-	wg.Wait()
-
 	// Prepare final counts
-	finalCounts := fmt.Sprintf("\033[2K\rSkipped: %d\tUpdated: %d\tChecked: %d", atomic.LoadUint32(&skipped), atomic.LoadUint32(&updated), uint32(int(atomic.LoadUint32(&checked))))
+	finalCounts := fmt.Sprintf("\033[2K\rSkipped: %d\tUpdated: %d\tChecked: %d", skipped, updated, checked)
 	if errors > 0 {
-		finalCounts += fmt.Sprintf("\tErrors: %d", atomic.LoadUint32(&errors))
+		finalCounts += fmt.Sprintf("\tErrors: %d", errors)
+	}
+	if len(hookErrors) > 0 {
+		finalCounts += fmt.Sprintf("\tHook errors: %d", len(hookErrors))
+	}
+	if ctx.Err() != nil {
+		finalCounts += "\tInterrupted"
 	}
 	// Print final counts
 	fmt.Println(finalCounts)
-	for error := range errorMessages {
-		fmt.Println(error)
+	for _, errorMessage := range errorMessages {
+		fmt.Println(errorMessage)
 	}
+	printHookErrors(hookErrors)
 
 	return nil
 }
-
-// getLocalSHA256 calculates the SHA256 checksum of the local file.
-func getLocalSHA256(filePath string) (string, error) {
-	// Open the file for reading
-	file, err := os.Open(filePath)
-	if err != nil {
-		return "", fmt.Errorf("failed to open file: %v", err)
-	}
-	defer file.Close()
-
-	// Calculate SHA256 checksum
-	hasher := sha256.New()
-	if _, err := io.Copy(hasher, file); err != nil {
-		return "", fmt.Errorf("failed to calculate SHA256: %v", err)
-	}
-	sha256Checksum := hex.EncodeToString(hasher.Sum(nil))
-
-	return sha256Checksum, nil
-}
-
-func checkDifferences(localSHA256, remoteSHA256 string) int {
-	if localSHA256 != remoteSHA256 {
-		return 1
-	}
-	return 0
-}
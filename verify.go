@@ -0,0 +1,36 @@
+// verify.go // This file implements the "verify" command //>
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/xplshn/bigdl/pkg/bigdl"
+)
+
+// verify checks the integrity of the installed binaries (all of them if none is given). It exits with a non-zero code if any was modified or is of unknown origin.
+func verify(ctx context.Context, binaryNames []string) {
+	results, err := newClient().Verify(ctx, binaryNames)
+	if err != nil && results == nil {
+		errorOut("%v\n", err)
+	}
+
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+			fmt.Printf("[error] %s: %v\n", result.Name, result.Err)
+			continue
+		}
+		if result.Status == bigdl.VerifyModified || result.Status == bigdl.VerifyUnknownOrigin {
+			failed++
+		}
+		fmt.Printf("[%s] %s: %s\n", result.Status, result.Name, result.Message)
+	}
+	if err != nil {
+		errorOut("%v\n", err)
+	}
+	if failed > 0 {
+		errorOut("%d binar(y|ies) failed verification\n", failed)
+	}
+}